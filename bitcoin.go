@@ -0,0 +1,386 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+)
+
+// -----------------------------------------------------------------------
+// Bitcoin compatibility
+// -----------------------------------------------------------------------
+
+// doubleSHA256 implements hash.Hash as SHA-256 applied twice, as Bitcoin does for
+// transaction ids, block hashes and merkle nodes.
+type doubleSHA256 struct {
+	hash.Hash
+}
+
+// newDoubleSHA256 returns a new hash.Hash computing SHA-256(SHA-256(data)).
+func newDoubleSHA256() hash.Hash {
+	return doubleSHA256{Hash: sha256.New()}
+}
+
+// Sum appends the double SHA-256 of the data written so far to @b.
+func (d doubleSHA256) Sum(b []byte) []byte {
+	first := d.Hash.Sum(nil)
+	second := sha256.Sum256(first)
+	return append(b, second[:]...)
+}
+
+// sha256d returns the double SHA-256 of @data.
+func sha256d(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// TxIDFromHex decodes a transaction id or block hash given in the usual (reversed)
+// hex notation into internal byte order.
+func TxIDFromHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != sha256.Size {
+		return nil, errors.New("error: transaction id must be 32 bytes")
+	}
+	return reverseBytes(b), nil
+}
+
+// TxIDToHex encodes a transaction id or block hash in internal byte order into the usual
+// (reversed) hex notation.
+func TxIDToHex(b []byte) string {
+	return hex.EncodeToString(reverseBytes(b))
+}
+
+// reverseBytes returns a reversed copy of @b.
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// NewBitcoinTree creates a new Merkle Tree from the transaction ids @txids in internal byte
+// order using double SHA-256. The leafs are ByteContents, such that a leaf hash is its txid.
+// Note that Bitcoin defines the merkle root of a block with a single transaction as the
+// txid itself, use BitcoinMerkleRoot to obtain block merkle roots.
+func NewBitcoinTree(txids [][]byte) (*MerkleTree, error) {
+	var cs []Content
+	for _, txid := range txids {
+		if len(txid) != sha256.Size {
+			return nil, errors.New("error: transaction id must be 32 bytes")
+		}
+		cs = append(cs, ByteContent{Content: txid})
+	}
+	return NewTreeWithHashStrategy(cs, "sha256d")
+}
+
+// BitcoinMerkleRoot returns the merkle root of a block with the transaction ids @txids,
+// all in internal byte order.
+func BitcoinMerkleRoot(txids [][]byte) ([]byte, error) {
+	if len(txids) == 1 {
+		if len(txids[0]) != sha256.Size {
+			return nil, errors.New("error: transaction id must be 32 bytes")
+		}
+		return txids[0], nil
+	}
+	t, err := NewBitcoinTree(txids)
+	if err != nil {
+		return nil, err
+	}
+	return t.MerkleRoot, nil
+}
+
+// PartialMerkleTree is a BIP37 partial merkle tree proving that a subset of the
+// transactions of a block is part of the block's merkle root.
+type PartialMerkleTree struct {
+	NumTransactions uint32
+	Hashes          [][]byte
+	Flags           []bool
+}
+
+// NewPartialMerkleTree creates a PartialMerkleTree over the transaction ids @txids in
+// internal byte order, proving the transactions for which @matches is true.
+func NewPartialMerkleTree(txids [][]byte, matches []bool) (*PartialMerkleTree, error) {
+	if len(txids) == 0 {
+		return nil, errors.New("error: cannot construct partial merkle tree with no transactions")
+	}
+	if len(txids) != len(matches) {
+		return nil, errors.New("error: transaction ids and matches differ in length")
+	}
+	pmt := &PartialMerkleTree{NumTransactions: uint32(len(txids))}
+	height := 0
+	for pmt.treeWidth(height) > 1 {
+		height++
+	}
+	pmt.traverseAndBuild(height, 0, txids, matches)
+	return pmt, nil
+}
+
+// treeWidth returns the number of nodes at @height, leafs having height 0.
+func (pmt *PartialMerkleTree) treeWidth(height int) uint32 {
+	return uint32((uint64(pmt.NumTransactions) + (1 << uint(height)) - 1) >> uint(height))
+}
+
+// calcHash returns the hash of the node at @height and position @pos.
+func (pmt *PartialMerkleTree) calcHash(height int, pos uint32, txids [][]byte) []byte {
+	if height == 0 {
+		return txids[pos]
+	}
+	left := pmt.calcHash(height-1, pos*2, txids)
+	right := left
+	if pos*2+1 < pmt.treeWidth(height-1) {
+		right = pmt.calcHash(height-1, pos*2+1, txids)
+	}
+	return sha256d(append(append([]byte{}, left...), right...))
+}
+
+// traverseAndBuild records flags and hashes depth first, descending only into
+// subtrees containing matched transactions.
+func (pmt *PartialMerkleTree) traverseAndBuild(height int, pos uint32, txids [][]byte, matches []bool) {
+	parentOfMatch := false
+	for p := pos << uint(height); p < (pos+1)<<uint(height) && p < pmt.NumTransactions; p++ {
+		parentOfMatch = parentOfMatch || matches[p]
+	}
+	pmt.Flags = append(pmt.Flags, parentOfMatch)
+	if height == 0 || !parentOfMatch {
+		pmt.Hashes = append(pmt.Hashes, pmt.calcHash(height, pos, txids))
+		return
+	}
+	pmt.traverseAndBuild(height-1, pos*2, txids, matches)
+	if pos*2+1 < pmt.treeWidth(height-1) {
+		pmt.traverseAndBuild(height-1, pos*2+1, txids, matches)
+	}
+}
+
+// extractor holds the state of a traversal extracting matches from a PartialMerkleTree.
+type extractor struct {
+	bitsUsed int
+	hashUsed int
+	matches  [][]byte
+	indexes  []uint32
+}
+
+// traverseAndExtract consumes flags and hashes depth first and returns the hash of the
+// node at @height and position @pos.
+func (pmt *PartialMerkleTree) traverseAndExtract(height int, pos uint32, e *extractor) ([]byte, error) {
+	if e.bitsUsed >= len(pmt.Flags) {
+		return nil, errors.New("error: partial merkle tree overflowed flags")
+	}
+	parentOfMatch := pmt.Flags[e.bitsUsed]
+	e.bitsUsed++
+	if height == 0 || !parentOfMatch {
+		if e.hashUsed >= len(pmt.Hashes) {
+			return nil, errors.New("error: partial merkle tree overflowed hashes")
+		}
+		h := pmt.Hashes[e.hashUsed]
+		e.hashUsed++
+		if height == 0 && parentOfMatch {
+			e.matches = append(e.matches, h)
+			e.indexes = append(e.indexes, pos)
+		}
+		return h, nil
+	}
+	left, err := pmt.traverseAndExtract(height-1, pos*2, e)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < pmt.treeWidth(height-1) {
+		right, err = pmt.traverseAndExtract(height-1, pos*2+1, e)
+		if err != nil {
+			return nil, err
+		}
+		// Identical siblings allow to forge trees with duplicated transactions (CVE-2012-2459).
+		if bytes.Equal(left, right) {
+			return nil, errors.New("error: partial merkle tree has identical siblings")
+		}
+	}
+	return sha256d(append(append([]byte{}, left...), right...)), nil
+}
+
+// ExtractMatches validates the partial merkle tree and returns its merkle root along with
+// the matched transaction ids and their positions in the block.
+func (pmt *PartialMerkleTree) ExtractMatches() ([]byte, [][]byte, []uint32, error) {
+	if pmt.NumTransactions == 0 {
+		return nil, nil, nil, errors.New("error: partial merkle tree has no transactions")
+	}
+	if len(pmt.Hashes) > int(pmt.NumTransactions) {
+		return nil, nil, nil, errors.New("error: partial merkle tree has more hashes than transactions")
+	}
+	if len(pmt.Flags) < len(pmt.Hashes) {
+		return nil, nil, nil, errors.New("error: partial merkle tree has fewer flags than hashes")
+	}
+	height := 0
+	for pmt.treeWidth(height) > 1 {
+		height++
+	}
+	e := &extractor{}
+	root, err := pmt.traverseAndExtract(height, 0, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if (e.bitsUsed+7)/8 != (len(pmt.Flags)+7)/8 {
+		return nil, nil, nil, errors.New("error: partial merkle tree has unused flags")
+	}
+	if e.hashUsed != len(pmt.Hashes) {
+		return nil, nil, nil, errors.New("error: partial merkle tree has unused hashes")
+	}
+	return root, e.matches, e.indexes, nil
+}
+
+// MarshalBinary encodes the partial merkle tree in the wire format of the merkleblock message.
+func (pmt *PartialMerkleTree) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, pmt.NumTransactions)
+	writeCompactSize(&buf, uint64(len(pmt.Hashes)))
+	for _, h := range pmt.Hashes {
+		buf.Write(h)
+	}
+	flags := make([]byte, (len(pmt.Flags)+7)/8)
+	for i, f := range pmt.Flags {
+		if f {
+			flags[i/8] |= 1 << uint(i%8)
+		}
+	}
+	writeCompactSize(&buf, uint64(len(flags)))
+	buf.Write(flags)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a partial merkle tree from the wire format of the merkleblock message.
+func (pmt *PartialMerkleTree) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, &pmt.NumTransactions); err != nil {
+		return err
+	}
+	numHashes, err := readCompactSize(r)
+	if err != nil {
+		return err
+	}
+	if numHashes > uint64(r.Len())/sha256.Size {
+		return errors.New("error: partial merkle tree truncated")
+	}
+	pmt.Hashes = make([][]byte, numHashes)
+	for i := range pmt.Hashes {
+		pmt.Hashes[i] = make([]byte, sha256.Size)
+		r.Read(pmt.Hashes[i])
+	}
+	numFlagBytes, err := readCompactSize(r)
+	if err != nil {
+		return err
+	}
+	if numFlagBytes != uint64(r.Len()) {
+		return errors.New("error: partial merkle tree flag bytes do not match length")
+	}
+	flags := make([]byte, numFlagBytes)
+	r.Read(flags)
+	pmt.Flags = make([]bool, len(flags)*8)
+	for i := range pmt.Flags {
+		pmt.Flags[i] = flags[i/8]&(1<<uint(i%8)) != 0
+	}
+	return nil
+}
+
+// MerkleBlock is a BIP37 merkleblock message: a block header along with a partial merkle
+// tree proving transactions against the header's merkle root.
+type MerkleBlock struct {
+	Header []byte
+	Tree   PartialMerkleTree
+}
+
+// blockHeaderSize is the size of a serialized block header in bytes.
+const blockHeaderSize = 80
+
+// BlockHash returns the hash of the block in internal byte order.
+func (mb *MerkleBlock) BlockHash() []byte {
+	return sha256d(mb.Header)
+}
+
+// MerkleRoot returns the merkle root committed to by the block header in internal byte order.
+func (mb *MerkleBlock) MerkleRoot() []byte {
+	return mb.Header[36:68]
+}
+
+// MarshalBinary encodes the merkleblock message.
+func (mb *MerkleBlock) MarshalBinary() ([]byte, error) {
+	if len(mb.Header) != blockHeaderSize {
+		return nil, errors.New("error: block header must be 80 bytes")
+	}
+	tree, err := mb.Tree.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, mb.Header...), tree...), nil
+}
+
+// UnmarshalBinary decodes a merkleblock message.
+func (mb *MerkleBlock) UnmarshalBinary(data []byte) error {
+	if len(data) < blockHeaderSize {
+		return errors.New("error: merkleblock truncated")
+	}
+	mb.Header = append([]byte{}, data[:blockHeaderSize]...)
+	return mb.Tree.UnmarshalBinary(data[blockHeaderSize:])
+}
+
+// Verify validates the partial merkle tree against the header's merkle root and returns
+// the matched transaction ids in internal byte order.
+func (mb *MerkleBlock) Verify() ([][]byte, error) {
+	if len(mb.Header) != blockHeaderSize {
+		return nil, errors.New("error: block header must be 80 bytes")
+	}
+	root, matches, _, err := mb.Tree.ExtractMatches()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, mb.MerkleRoot()) {
+		return nil, errors.New("error: partial merkle tree does not match merkle root of block")
+	}
+	return matches, nil
+}
+
+// writeCompactSize writes @n as a Bitcoin variable length integer.
+func writeCompactSize(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(0xfd)
+		binary.Write(buf, binary.LittleEndian, uint16(n))
+	case n <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		binary.Write(buf, binary.LittleEndian, uint32(n))
+	default:
+		buf.WriteByte(0xff)
+		binary.Write(buf, binary.LittleEndian, n)
+	}
+}
+
+// readCompactSize reads a Bitcoin variable length integer from @r.
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch prefix {
+	case 0xfd:
+		var n uint16
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return uint64(n), err
+	case 0xfe:
+		var n uint32
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return uint64(n), err
+	case 0xff:
+		var n uint64
+		err = binary.Read(r, binary.LittleEndian, &n)
+		return n, err
+	}
+	return uint64(prefix), nil
+}
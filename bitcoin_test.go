@@ -0,0 +1,282 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// Transactions of block 100000 in the usual hex notation.
+var block100000TxIDs = []string{
+	"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+	"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+	"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+	"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+}
+
+const (
+	block100000MerkleRoot = "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766"
+	block100000Hash       = "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506"
+	block100000PrevHash   = "000000000002d01c1fccc21636b607dfd930d31d01c3a62104612a1719011250"
+	genesisCoinbaseTxID   = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+func decodeTxIDs(t *testing.T, hexTxIDs []string) [][]byte {
+	var txids [][]byte
+	for _, s := range hexTxIDs {
+		txid, err := TxIDFromHex(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txids = append(txids, txid)
+	}
+	return txids
+}
+
+// block100000Header assembles the header of block 100000 from its fields.
+func block100000Header(t *testing.T) []byte {
+	prev, err := TxIDFromHex(block100000PrevHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := TxIDFromHex(block100000MerkleRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(1))
+	buf.Write(prev)
+	buf.Write(root)
+	binary.Write(&buf, binary.LittleEndian, []uint32{1293623863, 0x1b04864c, 274148111})
+	return buf.Bytes()
+}
+
+func TestBitcoinMerkleRoot(t *testing.T) {
+	tables := []struct {
+		txids []string
+		root  string
+	}{
+		{block100000TxIDs, block100000MerkleRoot},
+		{[]string{genesisCoinbaseTxID}, genesisCoinbaseTxID},
+	}
+	for i, table := range tables {
+		root, err := BitcoinMerkleRoot(decodeTxIDs(t, table.txids))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if TxIDToHex(root) != table.root {
+			t.Errorf("[case:%d] error: expected root %s got %s", i, table.root, TxIDToHex(root))
+		}
+	}
+}
+
+func TestBitcoinTree_MerklePath(t *testing.T) {
+	txids := decodeTxIDs(t, block100000TxIDs[:3])
+	tree, err := NewBitcoinTree(txids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, txid := range txids {
+		merklePath, index, err := tree.GetMerklePath(ByteContent{Content: txid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyMerklePath(txid, tree.MerkleRoot, merklePath, index, "sha256d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[tx:%d] error: expected valid merkle path", i)
+		}
+	}
+}
+
+func TestPartialMerkleTree(t *testing.T) {
+	txids := decodeTxIDs(t, block100000TxIDs)
+	tables := []struct {
+		txids   [][]byte
+		matches []bool
+	}{
+		{txids, []bool{false, false, true, false}},
+		{txids, []bool{true, false, false, true}},
+		{txids, []bool{false, false, false, false}},
+		{txids[:3], []bool{false, false, true}},
+		{txids[:1], []bool{true}},
+	}
+	for i, table := range tables {
+		pmt, err := NewPartialMerkleTree(table.txids, table.matches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := pmt.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded PartialMerkleTree
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		root, matched, indexes, err := decoded.ExtractMatches()
+		if err != nil {
+			t.Fatalf("[case:%d] error: unexpected error: %v", i, err)
+		}
+		expectedRoot, err := BitcoinMerkleRoot(table.txids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(root, expectedRoot) {
+			t.Errorf("[case:%d] error: expected root %x got %x", i, expectedRoot, root)
+		}
+		var j int
+		for k, match := range table.matches {
+			if !match {
+				continue
+			}
+			if j >= len(matched) || !bytes.Equal(matched[j], table.txids[k]) || indexes[j] != uint32(k) {
+				t.Errorf("[case:%d] error: expected match of transaction %d", i, k)
+			}
+			j++
+		}
+		if j != len(matched) {
+			t.Errorf("[case:%d] error: expected %d matches got %d", i, j, len(matched))
+		}
+	}
+}
+
+func TestPartialMerkleTree_Malformed(t *testing.T) {
+	txids := decodeTxIDs(t, block100000TxIDs)
+	pmt, err := NewPartialMerkleTree(txids, []bool{false, true, false, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []PartialMerkleTree{
+		{NumTransactions: 0},
+		{NumTransactions: pmt.NumTransactions, Hashes: pmt.Hashes[1:], Flags: pmt.Flags},
+		{NumTransactions: pmt.NumTransactions, Hashes: pmt.Hashes, Flags: pmt.Flags[:2]},
+		{NumTransactions: pmt.NumTransactions, Hashes: append(pmt.Hashes, pmt.Hashes[0]), Flags: pmt.Flags},
+		{NumTransactions: pmt.NumTransactions, Hashes: pmt.Hashes, Flags: append(pmt.Flags, make([]bool, 8)...)},
+		// duplicated transactions (CVE-2012-2459)
+		{NumTransactions: 2, Hashes: [][]byte{txids[0], txids[0]}, Flags: []bool{true, true, false}},
+	}
+	for i, table := range tables {
+		if _, _, _, err := table.ExtractMatches(); err == nil {
+			t.Errorf("[case:%d] error: expected error for malformed tree", i)
+		}
+	}
+}
+
+func TestMerkleBlock(t *testing.T) {
+	header := block100000Header(t)
+	pmt, err := NewPartialMerkleTree(decodeTxIDs(t, block100000TxIDs), []bool{false, true, false, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mb := MerkleBlock{Header: header, Tree: *pmt}
+	if TxIDToHex(mb.BlockHash()) != block100000Hash {
+		t.Fatalf("error: expected block hash %s got %s", block100000Hash, TxIDToHex(mb.BlockHash()))
+	}
+	data, err := mb.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded MerkleBlock
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := decoded.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || TxIDToHex(matches[0]) != block100000TxIDs[1] {
+		t.Errorf("error: expected match %s got %v", block100000TxIDs[1], matches)
+	}
+
+	other, err := NewPartialMerkleTree(decodeTxIDs(t, block100000TxIDs[:3]), []bool{false, true, false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := MerkleBlock{Header: header, Tree: *other}
+	if _, err := forged.Verify(); err == nil {
+		t.Errorf("error: expected error for tree not matching block")
+	}
+}
+
+func TestMerkleBlock_WireVectors(t *testing.T) {
+	tables := []struct {
+		data    string
+		txids   []string
+		matches []bool
+	}{
+		// Block 100000 matching its second transaction, assembled following BIP37: the
+		// flags 1, 1, 0, 1, 0 in depth-first order packed LSB first are 0x0b, and the
+		// hashes are those of the first two transactions and of the right inner node.
+		{
+			"0100000050120119172a610421a6c3011dd330d9df07b63616c2cc1f1cd00200" +
+				"000000006657a9252aacd5c0b2940996ecff952228c3067cc38d4885efb5a4ac" +
+				"4247e9f337221b4d4c86041b0f2b57100400000003876dd0a3ef4a2816ffd1c1" +
+				"2ab649825a958b0ff3bb3d6f3e1250f13ddbf0148cc40297f730dd7b5a99567e" +
+				"b8d27b78758f607507c52292d02d4031895b52f2ff49aef42d78e3e9999c9e6e" +
+				"c9e1dddd6cb880bf3b076a03be1318ca789089308e010b",
+			block100000TxIDs,
+			[]bool{false, true, false, false},
+		},
+		// A block with a single transaction, as encoded by the bloom package of btcutil
+		// (TestMerkleBlock3).
+		{
+			"0100000079cda856b143d9db2c1caff01d1aecc8630d30625d10e8b4b8b0000000000000" +
+				"b50cc069d6a3e33e3ff84a5c41d9d3febe7c770fdcc96b2c3ff60abe184f196367291b4d" +
+				"4c86041b8fa45d630100000001b50cc069d6a3e33e3ff84a5c41d9d3febe7c770fdcc96b" +
+				"2c3ff60abe184f19630101",
+			[]string{"63194f18be0af63f2c6bc9dc0f777cbefed3d9415c4af83f3ee3a3d669c00cb5"},
+			[]bool{true},
+		},
+	}
+	for i, table := range tables {
+		data, err := hex.DecodeString(table.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mb MerkleBlock
+		if err := mb.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		matches, err := mb.Verify()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for j, match := range table.matches {
+			if match {
+				want = append(want, table.txids[j])
+			}
+		}
+		if len(matches) != len(want) {
+			t.Fatalf("[case:%d] error: expected %d matches got %d", i, len(want), len(matches))
+		}
+		for j := range matches {
+			if TxIDToHex(matches[j]) != want[j] {
+				t.Errorf("[case:%d] error: expected match %s got %s", i, want[j], TxIDToHex(matches[j]))
+			}
+		}
+		encoded, err := mb.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, data) {
+			t.Errorf("[case:%d] error: expected re-encoding %x got %x", i, data, encoded)
+		}
+
+		pmt, err := NewPartialMerkleTree(decodeTxIDs(t, table.txids), table.matches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		built := MerkleBlock{Header: data[:blockHeaderSize], Tree: *pmt}
+		if encoded, err = built.MarshalBinary(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, data) {
+			t.Errorf("[case:%d] error: expected encoding %x got %x", i, data, encoded)
+		}
+	}
+}
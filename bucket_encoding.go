@@ -0,0 +1,291 @@
+package merkletree
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// -----------------------------------------------------------------------
+// Bucket encoding
+// -----------------------------------------------------------------------
+
+// The content of a StorageBucket is encoded as a header followed by the frames of its
+// items. Buckets of pools with the default encoding are written in version 1:
+//
+//	header: magic "\xffMTB" | version 1 | item count (4 bytes, little endian)
+//	frame:  item length (8 bytes, little endian) | item
+//
+// Buckets of pools with any other BucketEncoding are written in version 2:
+//
+//	header:  magic "\xffMTB" | version 2 | FrameEncoding | Compression | item count (4 bytes, little endian)
+//	payload: the frames, compressed as a whole by the Compression
+//	frame:   item length (8 bytes little endian or uvarint, per FrameEncoding) | item
+//
+// Content without the magic is read in the legacy format, which consists of the frames
+// of version 1 only and ends at the first frame of length zero. The magic read as a legacy
+// length prefix would announce an item larger than 1 GB, so the formats cannot be confused.
+//
+// The hash of a StorageBucket is calculated over its canonical form, which is the version
+// 1 encoding of its items, such that it does not depend on the encoding of the bucket.
+// Legacy content is its own canonical form.
+
+// bucketMagic starts the content of an encoded StorageBucket.
+var bucketMagic = []byte("\xffMTB")
+
+const (
+	// bucketHeaderLen is the length of the header of version 1.
+	bucketHeaderLen = 9
+	// bucketHeaderLenV2 is the length of the header of version 2.
+	bucketHeaderLenV2 = 11
+	// frameHeaderLen is the length of the fixed length prefix of an item.
+	frameHeaderLen = 8
+	// maxBucketPayload bounds the decompressed payload of buckets of unknown size.
+	maxBucketPayload = 1 << 26
+)
+
+// ErrFrameTruncated is returned if the content of a bucket ends within a frame.
+var ErrFrameTruncated = errors.New("frame error. bucket content is truncated")
+
+// ErrFrameOversized is returned if a frame is longer than the remaining bucket content.
+var ErrFrameOversized = errors.New("frame error. frame length exceeds bucket content")
+
+// FrameEncoding selects the length prefix of the items of a bucket.
+type FrameEncoding uint8
+
+const (
+	// FrameFixed prefixes items with their length as 8-byte little endian integer.
+	FrameFixed FrameEncoding = iota
+	// FrameUvarint prefixes items with their length as uvarint.
+	FrameUvarint
+)
+
+// Compression selects the compression of the frames of a bucket.
+type Compression uint8
+
+const (
+	// CompressionNone stores the frames uncompressed.
+	CompressionNone Compression = iota
+	// CompressionFlate compresses the frames with DEFLATE.
+	CompressionFlate
+	// CompressionGzip compresses the frames with gzip.
+	CompressionGzip
+)
+
+// BucketEncoding is the encoding of the buckets of a pool. The zero value is the default
+// encoding with fixed length prefixes and no compression.
+// The size of a bucket bounds its uncompressed frames.
+type BucketEncoding struct {
+	Frames      FrameEncoding
+	Compression Compression
+}
+
+// validate returns an error if the encoding is unknown.
+func (e BucketEncoding) validate() error {
+	if e.Frames > FrameUvarint {
+		return fmt.Errorf("frame error. unknown frame encoding %d", e.Frames)
+	}
+	if e.Compression > CompressionGzip {
+		return fmt.Errorf("frame error. unknown compression %d", e.Compression)
+	}
+	return nil
+}
+
+// appendFrameLen appends the length prefix of an item of @n bytes to @b.
+func (e BucketEncoding) appendFrameLen(b []byte, n int) []byte {
+	if e.Frames == FrameUvarint {
+		var buf [binary.MaxVarintLen64]byte
+		return append(b, buf[:binary.PutUvarint(buf[:], uint64(n))]...)
+	}
+	var buf [frameHeaderLen]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(n))
+	return append(b, buf[:]...)
+}
+
+// frameLen returns the length of the frame of an item of @n bytes.
+func (e BucketEncoding) frameLen(n int) int {
+	if e.Frames == FrameUvarint {
+		var buf [binary.MaxVarintLen64]byte
+		return binary.PutUvarint(buf[:], uint64(n)) + n
+	}
+	return frameHeaderLen + n
+}
+
+// encodeBucket returns the encoded content of a bucket of @count items with the frames
+// @frames in the encoding @enc.
+func encodeBucket(enc BucketEncoding, count uint32, frames []byte) []byte {
+	if enc == (BucketEncoding{}) {
+		out := make([]byte, bucketHeaderLen, bucketHeaderLen+len(frames))
+		copy(out, bucketMagic)
+		out[4] = 1
+		binary.LittleEndian.PutUint32(out[5:], count)
+		return append(out, frames...)
+	}
+	out := make([]byte, bucketHeaderLenV2, bucketHeaderLenV2+len(frames))
+	copy(out, bucketMagic)
+	out[4] = 2
+	out[5] = byte(enc.Frames)
+	out[6] = byte(enc.Compression)
+	binary.LittleEndian.PutUint32(out[7:], count)
+	buf := bytes.NewBuffer(out)
+	var w io.WriteCloser
+	switch enc.Compression {
+	case CompressionFlate:
+		// The level is valid and writes to a bytes.Buffer do not fail.
+		w, _ = flate.NewWriter(buf, flate.DefaultCompression)
+	case CompressionGzip:
+		w = gzip.NewWriter(buf)
+	default:
+		return append(out, frames...)
+	}
+	w.Write(frames)
+	w.Close()
+	return buf.Bytes()
+}
+
+// decodeBucket returns the items of the encoded bucket content @content. @limit bounds
+// the length of the decompressed frames, a @limit of 0 stands for maxBucketPayload.
+func decodeBucket(content []byte, limit uint64) ([][]byte, error) {
+	if !bytes.HasPrefix(content, bucketMagic) {
+		return decodeLegacyBucket(content)
+	}
+	if len(content) < bucketHeaderLen {
+		return nil, ErrFrameTruncated
+	}
+	var enc BucketEncoding
+	var count uint32
+	var rest []byte
+	switch content[4] {
+	case 1:
+		count = binary.LittleEndian.Uint32(content[5:])
+		rest = content[bucketHeaderLen:]
+	case 2:
+		if len(content) < bucketHeaderLenV2 {
+			return nil, ErrFrameTruncated
+		}
+		enc = BucketEncoding{Frames: FrameEncoding(content[5]), Compression: Compression(content[6])}
+		if err := enc.validate(); err != nil {
+			return nil, err
+		}
+		count = binary.LittleEndian.Uint32(content[7:])
+		var err error
+		if rest, err = decompress(enc.Compression, content[bucketHeaderLenV2:], limit); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("frame error. unknown bucket encoding version %d", content[4])
+	}
+	// Every item takes at least its length prefix, which bounds the allocation.
+	if uint64(count)*uint64(enc.frameLen(0)) > uint64(len(rest)) {
+		return nil, ErrFrameTruncated
+	}
+	data := make([][]byte, 0, count)
+	for i := uint32(0); i < count; i++ {
+		item, n, err := enc.readFrame(rest)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, item)
+		rest = rest[n:]
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("frame error. %d bytes after the last frame", len(rest))
+	}
+	return data, nil
+}
+
+// decompress returns the payload @payload decompressed by @c, which must not be longer
+// than @limit bytes.
+func decompress(c Compression, payload []byte, limit uint64) ([]byte, error) {
+	var r io.Reader
+	switch c {
+	case CompressionNone:
+		return payload, nil
+	case CompressionFlate:
+		fr := flate.NewReader(bytes.NewReader(payload))
+		defer fr.Close()
+		r = fr
+	case CompressionGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, ErrFrameTruncated
+		}
+		defer gr.Close()
+		r = gr
+	}
+	if limit == 0 {
+		limit = maxBucketPayload
+	}
+	frames, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("frame error. cannot decompress bucket content: %v", err)
+	}
+	if uint64(len(frames)) > limit {
+		return nil, ErrFrameOversized
+	}
+	return frames, nil
+}
+
+// decodeLegacyBucket returns the items of bucket content in the legacy format.
+func decodeLegacyBucket(content []byte) ([][]byte, error) {
+	var data [][]byte
+	for len(content) > 0 {
+		item, n, err := BucketEncoding{}.readFrame(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(item) == 0 {
+			break
+		}
+		data = append(data, item)
+		content = content[n:]
+	}
+	return data, nil
+}
+
+// readFrame reads the frame at the start of @b and returns its item and the length of
+// the frame.
+func (e BucketEncoding) readFrame(b []byte) ([]byte, int, error) {
+	var length uint64
+	var start int
+	if e.Frames == FrameUvarint {
+		length, start = binary.Uvarint(b)
+		if start == 0 {
+			return nil, 0, ErrFrameTruncated
+		}
+		if start < 0 {
+			return nil, 0, ErrFrameOversized
+		}
+	} else {
+		if len(b) < frameHeaderLen {
+			return nil, 0, ErrFrameTruncated
+		}
+		length, start = binary.LittleEndian.Uint64(b), frameHeaderLen
+	}
+	if length > uint64(len(b)-start) {
+		return nil, 0, ErrFrameOversized
+	}
+	end := start + int(length)
+	return append([]byte{}, b[start:end]...), end, nil
+}
+
+// canonicalContent returns the canonical form of the bucket content @content, see above.
+func canonicalContent(content []byte, limit uint64) ([]byte, error) {
+	if !bytes.HasPrefix(content, bucketMagic) || len(content) > 4 && content[4] == 1 {
+		return content, nil
+	}
+	data, err := decodeBucket(content, limit)
+	if err != nil {
+		return nil, err
+	}
+	var frames []byte
+	for _, item := range data {
+		frames = BucketEncoding{}.appendFrameLen(frames, len(item))
+		frames = append(frames, item...)
+	}
+	return encodeBucket(BucketEncoding{}, uint32(len(data)), frames), nil
+}
@@ -0,0 +1,207 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// legacyFrames returns @items in the legacy bucket format.
+func legacyFrames(items ...string) []byte {
+	var out []byte
+	for _, item := range items {
+		var prefix [8]byte
+		binary.LittleEndian.PutUint64(prefix[:], uint64(len(item)))
+		out = append(out, prefix[:]...)
+		out = append(out, item...)
+	}
+	return out
+}
+
+func TestBucketEncoding_Decode(t *testing.T) {
+	tables := []struct {
+		content []byte
+		items   []string
+		err     bool
+	}{
+		{encodeBucket(BucketEncoding{}, 0, nil), []string{}, false},
+		{encodeBucket(BucketEncoding{}, 3, legacyFrames("a", "", "bc")), []string{"a", "", "bc"}, false},
+		{encodeBucket(BucketEncoding{}, 2, legacyFrames("", "")), []string{"", ""}, false},
+		{legacyFrames("a", "bc"), []string{"a", "bc"}, false},
+		{legacyFrames("a", "", "bc"), []string{"a"}, false},
+		{nil, []string{}, false},
+		// truncated header, frame prefix and frame
+		{bucketMagic, nil, true},
+		{encodeBucket(BucketEncoding{}, 2, legacyFrames("a")), nil, true},
+		{encodeBucket(BucketEncoding{}, 1, legacyFrames("abc")[:9]), nil, true},
+		{legacyFrames("abc")[:4], nil, true},
+		// oversized frame and count
+		{append(legacyFrames("abc")[:8], 'a'), nil, true},
+		{encodeBucket(BucketEncoding{}, 1<<31, legacyFrames("a")), nil, true},
+		// trailing bytes
+		{encodeBucket(BucketEncoding{}, 1, legacyFrames("a", "b")), nil, true},
+		// unknown version
+		{append(append([]byte{}, bucketMagic...), 2, 0, 0, 0, 0), nil, true},
+	}
+	for i, table := range tables {
+		sb := StorageBucket{Content: table.content}
+		data, err := sb.ReadContent()
+		if (err != nil) != table.err {
+			t.Errorf("[case:%d] error: expected error %v got %v", i, table.err, err)
+			continue
+		}
+		if table.err {
+			continue
+		}
+		if len(data) != len(table.items) {
+			t.Errorf("[case:%d] error: expected %d items got %d", i, len(table.items), len(data))
+			continue
+		}
+		for j, item := range data {
+			if string(item) != table.items[j] {
+				t.Errorf("[case:%d] error: expected item %q got %q", i, table.items[j], item)
+			}
+		}
+	}
+}
+
+func TestBucketEncoding_EmptyItems(t *testing.T) {
+	b := NewBucket(64, "trades")
+	for _, item := range []string{"", "a", ""} {
+		if !b.WriteContent([]byte(item)) {
+			t.Fatalf("error: could not write %q", item)
+		}
+	}
+	sb := bucketToStorage(*b)
+	data, err := sb.ReadContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 || len(data[0]) != 0 || string(data[1]) != "a" || len(data[2]) != 0 {
+		t.Errorf("error: unexpected content %q", data)
+	}
+}
+
+// storageFor writes @items to a bucket of the pool @bp and returns its StorageBucket.
+func storageFor(t *testing.T, bp *BucketPool, items []string) StorageBucket {
+	b, err := bp.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if !b.WriteContent([]byte(item)) {
+			t.Fatalf("error: could not write %q", item)
+		}
+	}
+	return bucketToStorage(b)
+}
+
+func TestBucketEncoding_Encodings(t *testing.T) {
+	items := []string{"", "btc-usd 42000.5", "btc-usd 42000.5", "btc-usd 42001.0", strings.Repeat("x", 300)}
+	canonical, err := storageFor(t, NewBucketPool(1, 1024, "trades"), items).CalculateHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixedLen int
+	for _, frames := range []FrameEncoding{FrameFixed, FrameUvarint} {
+		for _, compression := range []Compression{CompressionNone, CompressionFlate, CompressionGzip} {
+			enc := BucketEncoding{Frames: frames, Compression: compression}
+			bp, err := NewBucketPoolWithEncoding(1, 1024, "trades", enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb := storageFor(t, bp, items)
+			data, err := sb.ReadContent()
+			if err != nil {
+				t.Fatalf("[encoding:%v] %v", enc, err)
+			}
+			if len(data) != len(items) {
+				t.Fatalf("[encoding:%v] error: expected %d items got %d", enc, len(items), len(data))
+			}
+			for i, item := range data {
+				if string(item) != items[i] {
+					t.Errorf("[encoding:%v] error: expected item %q got %q", enc, items[i], item)
+				}
+			}
+			hash, err := sb.CalculateHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(hash, canonical) {
+				t.Errorf("[encoding:%v] error: hash differs from canonical hash", enc)
+			}
+			switch {
+			case enc == BucketEncoding{}:
+				fixedLen = len(sb.Content)
+			case compression == CompressionNone:
+				if len(sb.Content) >= fixedLen {
+					t.Errorf("[encoding:%v] error: expected content shorter than %d got %d", enc, fixedLen, len(sb.Content))
+				}
+			default:
+				if len(sb.Content) >= fixedLen/2 {
+					t.Errorf("[encoding:%v] error: expected compressed content shorter than %d got %d", enc, fixedLen/2, len(sb.Content))
+				}
+			}
+		}
+	}
+	if _, err := NewBucketPoolWithEncoding(1, 1024, "trades", BucketEncoding{Compression: 3}); err == nil {
+		t.Errorf("error: expected error for unknown compression")
+	}
+}
+
+func TestBucketEncoding_UvarintCapacity(t *testing.T) {
+	// 64 bytes hold 4 items of 8 bytes with fixed prefixes, but 7 with uvarint prefixes.
+	for _, table := range []struct {
+		frames FrameEncoding
+		items  int
+	}{{FrameFixed, 4}, {FrameUvarint, 7}} {
+		bp, _ := NewBucketPoolWithEncoding(1, 64, "trades", BucketEncoding{Frames: table.frames})
+		b, _ := bp.Get()
+		var n int
+		for b.WriteContent([]byte("12345678")) {
+			n++
+		}
+		if n != table.items {
+			t.Errorf("[frames:%d] error: expected %d items got %d", table.frames, table.items, n)
+		}
+	}
+}
+
+func TestBucketEncoding_DecompressionLimit(t *testing.T) {
+	bp, _ := NewBucketPoolWithEncoding(1, 1<<20, "trades", BucketEncoding{Compression: CompressionGzip})
+	sb := storageFor(t, bp, []string{strings.Repeat("a", 1<<19)})
+	if _, err := sb.ReadContent(); err != nil {
+		t.Fatal(err)
+	}
+	sb.Size = 1 << 10
+	if _, err := sb.ReadContent(); err != ErrFrameOversized {
+		t.Errorf("error: expected ErrFrameOversized got %v", err)
+	}
+}
+
+func FuzzBucketEncoding_ReadContent(f *testing.F) {
+	f.Add(encodeBucket(BucketEncoding{}, 3, legacyFrames("a", "", "bc")))
+	f.Add(legacyFrames("abc", "de"))
+	f.Add(encodeBucket(BucketEncoding{}, 1<<31, nil))
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	f.Add(encodeBucket(BucketEncoding{Frames: FrameUvarint}, 2, []byte{1, 'a', 0}))
+	f.Add(encodeBucket(BucketEncoding{Frames: FrameUvarint, Compression: CompressionFlate}, 2, []byte{1, 'a', 0}))
+	f.Add(encodeBucket(BucketEncoding{Compression: CompressionGzip}, 1, legacyFrames("abc")))
+	f.Fuzz(func(t *testing.T, content []byte) {
+		sb := StorageBucket{Content: content}
+		data, err := sb.ReadContent()
+		sb.CalculateHash()
+		if err != nil || !bytes.HasPrefix(content, bucketMagic) || content[4] != 1 {
+			return
+		}
+		// Valid encodings are read back unchanged.
+		var frames []byte
+		for _, item := range data {
+			frames = append(frames, legacyFrames(string(item))...)
+		}
+		if !bytes.Equal(encodeBucket(BucketEncoding{}, uint32(len(data)), frames), content) {
+			t.Errorf("error: content %x does not round trip", content)
+		}
+	})
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -30,6 +30,9 @@ type Bucket struct {
 	// Timestamp is the time, the filled bucket is put into the pool
 	Timestamp time.Time
 	used      bool
+	// count is the number of items in the bucket
+	count    uint32
+	encoding BucketEncoding
 }
 
 // TODO: These two methods can be removed: Bucket does not have to implement Content,
@@ -48,17 +51,21 @@ func (b Bucket) CalculateHash() ([]byte, error) {
 // Equals is true if buckets are identical. Is needed for a bucket in
 // order to implement Content from merkle_tree.
 func (b Bucket) Equals(other Content) (bool, error) {
+	o, ok := other.(Bucket)
+	if !ok {
+		return false, nil
+	}
 	// Extend for other fields, but which? Do we need all fields?
-	if !bytes.Equal(b.Content.Bytes(), other.(Bucket).Content.Bytes()) {
+	if !bytes.Equal(b.Content.Bytes(), o.Content.Bytes()) {
 		return false, nil
 	}
-	if b.size != other.(Bucket).size {
+	if b.size != o.size {
 		return false, nil
 	}
-	if b.ID != other.(Bucket).ID {
+	if b.ID != o.ID {
 		return false, nil
 	}
-	if b.Topic != other.(Bucket).Topic {
+	if b.Topic != o.Topic {
 		return false, nil
 	}
 	return true, nil
@@ -73,8 +80,22 @@ type StorageBucket struct {
 	Size      uint64
 	ID        string
 	Timestamp time.Time
+	// HashVersion selects how the hash of the bucket is calculated, see CalculateHash
+	HashVersion HashVersion
 }
 
+// HashVersion is the version of the hashing scheme of a StorageBucket.
+type HashVersion uint8
+
+const (
+	// HashVersionContent hashes the content of a bucket only. It is the version of
+	// buckets stored before hash versions were introduced, such that their roots can
+	// still be verified.
+	HashVersionContent HashVersion = iota
+	// HashVersionMetadata binds the Topic, ID, Size and Timestamp of a bucket into its hash.
+	HashVersionMetadata
+)
+
 // Custom marshaler for StorageBucket type
 func (sb StorageBucket) MarshalJSON() ([]byte, error) {
 	type _StorageBucket StorageBucket
@@ -90,9 +111,52 @@ func (sb StorageBucket) MarshalJSON() ([]byte, error) {
 
 // CalculateHash calculates the hash of a StorageBucket. Is needed for a StorageBucket in
 // order to implement Content from merkle_tree.
+// The content is hashed in its canonical form, see bucket_encoding.go. With
+// HashVersionContent, the hash is the SHA-256 hash of the content. With
+// HashVersionMetadata, it is the SHA-256 hash of
+//
+//	version (1 byte) | len(Topic) (8 bytes) | Topic | len(ID) (8 bytes) | ID | Size (8 bytes) |
+//	Timestamp seconds (8 bytes) | Timestamp nanoseconds (4 bytes) | SHA-256 hash of the content
+//
+// with all integers big endian and the Timestamp in seconds since the Unix epoch.
 func (sb StorageBucket) CalculateHash() ([]byte, error) {
+	content, err := canonicalContent(sb.Content, sb.Size)
+	if err != nil {
+		return nil, err
+	}
 	h := sha256.New()
-	if _, err := h.Write(sb.Content); err != nil {
+	if _, err := h.Write(content); err != nil {
+		return nil, err
+	}
+	switch sb.HashVersion {
+	case HashVersionContent:
+		return h.Sum(nil), nil
+	case HashVersionMetadata:
+		return sb.metadataHash(h.Sum(nil))
+	}
+	return nil, fmt.Errorf("error: unknown hash version %d", sb.HashVersion)
+}
+
+// metadataHash returns the hash of HashVersionMetadata for the content hash @contentHash.
+func (sb StorageBucket) metadataHash(contentHash []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var num [8]byte
+	buf.WriteByte(byte(HashVersionMetadata))
+	binary.BigEndian.PutUint64(num[:], uint64(len(sb.Topic)))
+	buf.Write(num[:])
+	buf.WriteString(sb.Topic)
+	binary.BigEndian.PutUint64(num[:], uint64(len(sb.ID)))
+	buf.Write(num[:])
+	buf.WriteString(sb.ID)
+	binary.BigEndian.PutUint64(num[:], sb.Size)
+	buf.Write(num[:])
+	binary.BigEndian.PutUint64(num[:], uint64(sb.Timestamp.Unix()))
+	buf.Write(num[:])
+	binary.BigEndian.PutUint32(num[:4], uint32(sb.Timestamp.Nanosecond()))
+	buf.Write(num[:4])
+	buf.Write(contentHash)
+	h := sha256.New()
+	if _, err := h.Write(buf.Bytes()); err != nil {
 		return nil, err
 	}
 	return h.Sum(nil), nil
@@ -101,17 +165,24 @@ func (sb StorageBucket) CalculateHash() ([]byte, error) {
 // Equals is true if StorageBuckets are identical. Is needed for a StorageBucket in
 // order to implement Content from merkle_tree.
 func (sb StorageBucket) Equals(other Content) (bool, error) {
+	o, ok := asStorageBucket(other)
+	if !ok {
+		return false, nil
+	}
 	// Extend for other fields, but which? Do we need all fields?
-	if !bytes.Equal(sb.Content, other.(StorageBucket).Content) {
+	if !bytes.Equal(sb.Content, o.Content) {
 		return false, nil
 	}
-	if sb.Size != other.(StorageBucket).Size {
+	if sb.Size != o.Size {
 		return false, nil
 	}
-	if sb.ID != other.(StorageBucket).ID {
+	if sb.ID != o.ID {
 		return false, nil
 	}
-	if sb.Topic != other.(StorageBucket).Topic {
+	if sb.Topic != o.Topic {
+		return false, nil
+	}
+	if sb.HashVersion != o.HashVersion || !sb.Timestamp.Equal(o.Timestamp) {
 		return false, nil
 	}
 	return true, nil
@@ -120,20 +191,36 @@ func (sb StorageBucket) Equals(other Content) (bool, error) {
 // bucketToStorage converts a bucket to a StorageBucket, ready for marshaling for influx.
 func bucketToStorage(b Bucket) (sb StorageBucket) {
 
-	sb.Content = b.Content.Bytes()
+	sb.Content = encodeBucket(b.encoding, b.count, b.Content.Bytes())
 	sb.Topic = b.Topic
 	sb.Size = b.size
 	sb.ID = b.ID
 	sb.Timestamp = b.Timestamp
+	sb.HashVersion = HashVersionMetadata
 
 	return
 }
 
+// asStorageBucket returns the StorageBucket held by the Content @c. Leafs of unmarshaled
+// trees hold pointers to StorageBuckets, see newContent.
+func asStorageBucket(c Content) (StorageBucket, bool) {
+	switch sb := c.(type) {
+	case StorageBucket:
+		return sb, true
+	case *StorageBucket:
+		if sb != nil {
+			return *sb, true
+		}
+	}
+	return StorageBucket{}, false
+}
+
 // BucketPool implements a leaky pool of Buckets in the form of a bounded channel.
 type BucketPool struct {
-	c     chan Bucket
-	width uint64
-	Topic string
+	c        chan Bucket
+	width    uint64
+	encoding BucketEncoding
+	Topic    string
 }
 
 // NewBucket creates a new bucket of size @size in bytes.
@@ -145,20 +232,42 @@ func NewBucket(size uint64, topic string) (b *Bucket) {
 	}
 }
 
+// newBucket creates a new empty bucket of the pool.
+func (bp *BucketPool) newBucket() *Bucket {
+	b := NewBucket(bp.width, bp.Topic)
+	b.encoding = bp.encoding
+	return b
+}
+
 // NewBucketPool creates a new BucketPool bounded to the length @maxNum.
 // It is initialized with empty Buckets of capacity @size.
 func NewBucketPool(maxNum uint64, size uint64, topic string) (bp *BucketPool) {
-	bp = &BucketPool{
-		c:     make(chan Bucket, maxNum),
-		width: size,
-		Topic: topic,
+	bp, _ = NewBucketPoolWithEncoding(maxNum, size, topic, BucketEncoding{})
+	return
+}
+
+// NewBucketPoolWithEncoding creates a new BucketPool bounded to the length @maxNum, whose
+// buckets are encoded with @enc. It is initialized with empty Buckets of capacity @size.
+func NewBucketPoolWithEncoding(maxNum uint64, size uint64, topic string, enc BucketEncoding) (*BucketPool, error) {
+	if err := enc.validate(); err != nil {
+		return nil, err
+	}
+	bp := &BucketPool{
+		c:        make(chan Bucket, maxNum),
+		width:    size,
+		encoding: enc,
+		Topic:    topic,
 	}
 	// Fill channel with empty buckets
 	for i := 0; i < int(maxNum); i++ {
-		bucket := NewBucket(size, topic)
-		bp.c <- *bucket
+		bp.c <- *bp.newBucket()
 	}
-	return
+	return bp, nil
+}
+
+// Encoding returns the encoding of the buckets of the pool.
+func (bp *BucketPool) Encoding() BucketEncoding {
+	return bp.encoding
 }
 
 // Size returns the size of a bucket
@@ -187,10 +296,10 @@ func (bp *BucketPool) Get() (b Bucket, err error) {
 			// In this case, all buckets from the pool have been used and a new pool
 			// should be created
 			bp.c <- b
-			return *NewBucket(bp.width, bp.Topic), errors.New("size error. pool is exhausted")
+			return *bp.newBucket(), ErrPoolExhausted
 		}
 	default:
-		return *NewBucket(bp.width, bp.Topic), errors.New("size error. pool is exhausted")
+		return *bp.newBucket(), ErrPoolExhausted
 		// fmt.Println("make new bucket")
 		// b = *NewBucket(bp.width)
 	}
@@ -217,45 +326,33 @@ func (bp *BucketPool) Put(b Bucket) bool {
 
 // WriteContent appends a byte slice to a bucket if there is enough space.
 // Does not write and returns false if there isn't.
-// Contents are separated by leading 64bit unsigned integers.
+// Contents are separated by leading length prefixes, 64bit unsigned integers by default.
+// The size of the bucket does not include the header added by bucketToStorage, see
+// bucket_encoding.go.
 func (b *Bucket) WriteContent(bs []byte) bool {
-	if b.Content.Len()+len(bs)+8 > int(b.Size()) {
+	if !b.fits(bs) {
 		return false
 	}
-	// Store length of content as 8-byte array
-	lenPrefix := make([]byte, 8)
-	binary.LittleEndian.PutUint64(lenPrefix, uint64(len(bs)))
 	// Write length and content
-	b.Content.Write(lenPrefix)
+	b.Content.Write(b.encoding.appendFrameLen(nil, len(bs)))
 	b.Content.Write(bs)
 
 	b.used = true
+	b.count++
 	return true
 
 }
 
+// fits returns true if there is enough space left in the bucket to write @bs.
+func (b *Bucket) fits(bs []byte) bool {
+	return b.Content.Len()+b.encoding.frameLen(len(bs)) <= int(b.Size())
+}
+
 // ReadContent returns the content of a storage bucket.
 // Each byte slice correponds to a marshaled data point such as an
-// interest rate or a trade.
+// interest rate or a trade. Returns an error if the content is not a valid encoding.
 func (sb *StorageBucket) ReadContent() (data [][]byte, err error) {
-	buf := bytes.NewBuffer(sb.Content)
-	readOn := true
-	for readOn {
-		// get length of content byte slice by reading the prefix
-		lenPrefix := make([]byte, 8)
-		buf.Read(lenPrefix)
-		lenContent := binary.LittleEndian.Uint64(lenPrefix)
-		if lenContent > 0 {
-			// In case there is content read it...
-			content := make([]byte, lenContent)
-			_, err = buf.Read(content)
-			data = append(data, [][]byte{content}...)
-		} else {
-			// ...otherwise stop reading
-			readOn = false
-		}
-	}
-	return
+	return decodeBucket(sb.Content, sb.Size)
 }
 
 // MakeTree returns a Merkle tree built from the Buckets in the pool @bp
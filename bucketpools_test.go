@@ -3,7 +3,9 @@ package merkletree
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestBucketpools_CalculateHash(t *testing.T) {
@@ -162,10 +164,88 @@ func TestBucketpools_ReadContent(t *testing.T) {
 	}
 	storageBucket1 := bucketToStorage(*bucket1)
 
-	content, _ := storageBucket1.ReadContent()
+	content, err := storageBucket1.ReadContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content) != len(teststrings) {
+		t.Fatalf("error: got %d items but expected %d", len(content), len(teststrings))
+	}
 	for i := 0; i < len(content); i++ {
 		if string(content[i]) != teststrings[i] {
 			t.Errorf("error: got %s but expected %s", content[i], teststrings[i])
 		}
 	}
 }
+
+func TestBucketpools_HashVersion(t *testing.T) {
+	b := NewBucket(64, "trades")
+	b.WriteContent([]byte("btc-usd 42000.5"))
+	b.ID = "b1"
+	b.Timestamp = time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+	sb := bucketToStorage(*b)
+	if sb.HashVersion != HashVersionMetadata {
+		t.Fatalf("error: expected hash version %d got %d", HashVersionMetadata, sb.HashVersion)
+	}
+	tampered := []func(sb *StorageBucket){
+		func(sb *StorageBucket) { sb.Topic = "rates" },
+		func(sb *StorageBucket) { sb.ID = "b2" },
+		func(sb *StorageBucket) { sb.Size = 65 },
+		func(sb *StorageBucket) { sb.Timestamp = sb.Timestamp.Add(time.Nanosecond) },
+	}
+	for i, tamper := range tampered {
+		for _, version := range []HashVersion{HashVersionContent, HashVersionMetadata} {
+			original := sb
+			original.HashVersion = version
+			changed := original
+			tamper(&changed)
+			h1, err := original.CalculateHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			h2, err := changed.CalculateHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(h1, h2) != (version == HashVersionContent) {
+				t.Errorf("[case:%d] error: unexpected hash for tampered metadata with hash version %d", i, version)
+			}
+		}
+	}
+
+	// The same time in another location has the same hash.
+	local := sb
+	local.Timestamp = sb.Timestamp.In(time.FixedZone("UTC+2", 2*60*60))
+	h1, _ := sb.CalculateHash()
+	h2, _ := local.CalculateHash()
+	if !bytes.Equal(h1, h2) {
+		t.Errorf("error: expected hash independent of time zone")
+	}
+
+	sb.HashVersion = 2
+	if _, err := sb.CalculateHash(); err == nil {
+		t.Errorf("error: expected error for unknown hash version")
+	}
+}
+
+func TestBucketpools_LegacyRoot(t *testing.T) {
+	// Trees of buckets marshaled before hash versions carry no HashVersion and are
+	// hashed by their content only.
+	legacy := `{"Root":null,"Leafs":[{"C":{"_type":"StorageBucket","Content":"AQAAAAAAAABh","Topic":"trades","Size":16,"ID":"","Timestamp":"2021-03-04T05:06:07Z"}}],"HashStrategy":"sha256"}`
+	var tree MerkleTree
+	if err := json.Unmarshal([]byte(legacy), &tree); err != nil {
+		t.Fatal(err)
+	}
+	sb, _ := asStorageBucket(tree.Leafs[0].C)
+	if sb.HashVersion != HashVersionContent {
+		t.Errorf("error: expected hash version %d got %d", HashVersionContent, sb.HashVersion)
+	}
+	h := sha256.Sum256(sb.Content)
+	hash, err := sb.CalculateHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(hash, h[:]) {
+		t.Errorf("error: expected legacy content hash")
+	}
+}
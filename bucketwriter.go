@@ -0,0 +1,147 @@
+package merkletree
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrItemTooLarge is returned if an item does not fit into an empty bucket.
+var ErrItemTooLarge = errors.New("size error. item does not fit into bucket")
+
+// ErrPoolExhausted is returned if all buckets of a pool have been used.
+var ErrPoolExhausted = errors.New("size error. pool is exhausted")
+
+// BucketWriter appends items to the buckets of a BucketPool and is safe for concurrent
+// use by multiple producers of a topic. The buckets are filled in order: if an item does
+// not fit into the current bucket, the bucket is put back into the pool and the item is
+// written to the next one. Items are never split across buckets.
+// The pool must not be used directly while it is written to by a BucketWriter.
+type BucketWriter struct {
+	mu      sync.Mutex
+	pool    *BucketPool
+	current *Bucket
+	written bool
+	wal     *PoolWAL
+	// sealed is the tree of a seal that could not be recorded in wal.
+	sealed *MerkleTree
+}
+
+// NewBucketWriter creates a BucketWriter filling the buckets of the pool @bp.
+func NewBucketWriter(bp *BucketPool) *BucketWriter {
+	return &BucketWriter{pool: bp}
+}
+
+// Pool returns the pool of the writer.
+func (w *BucketWriter) Pool() *BucketPool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pool
+}
+
+// RecoverBucketWriter creates a BucketWriter filling the buckets of the empty pool @bp,
+// which records its writes and seals in the write-ahead log @wal. The items written since
+// the last seal recorded in @wal are written to the pool again, which restores the
+// partially filled buckets if @bp is created like the pool of the crashed writer.
+func RecoverBucketWriter(wal *PoolWAL, bp *BucketPool) (*BucketWriter, error) {
+	w := NewBucketWriter(bp)
+	for _, item := range wal.Pending() {
+		if err := w.Write(item); err != nil {
+			return nil, err
+		}
+	}
+	w.wal = wal
+	return w, nil
+}
+
+// Write appends the item @bs to the current bucket, or to the next bucket of the pool if
+// the current one is full. Returns ErrItemTooLarge if the item does not fit into an empty
+// bucket and ErrPoolExhausted if there is no bucket left in the pool.
+// If the writer has a write-ahead log, the item is recorded in it before it is written to
+// the bucket. If recording fails, the item is not written and the error is returned.
+func (w *BucketWriter) Write(bs []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.sealed != nil {
+		return ErrPoolExhausted
+	}
+	// The pool is only replaced by Seal under w.mu, so this is the pool written to.
+	if uint64(w.pool.encoding.frameLen(len(bs))) > w.pool.width {
+		return ErrItemTooLarge
+	}
+	if w.current == nil || !w.current.fits(bs) {
+		w.putCurrent()
+		b, err := w.pool.Get()
+		if err != nil {
+			return err
+		}
+		w.current = &b
+		if !w.current.fits(bs) {
+			return ErrItemTooLarge
+		}
+	}
+	if w.wal != nil {
+		if err := w.wal.LogWrite(bs); err != nil {
+			return err
+		}
+	}
+	w.current.WriteContent(bs)
+	w.written = true
+	return nil
+}
+
+// Written returns true if any item was written.
+func (w *BucketWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written
+}
+
+// Flush puts the current bucket back into the pool. It must be called before the tree of
+// the pool is built by MakeTree. Subsequent writes start with the next bucket of the pool.
+func (w *BucketWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.putCurrent()
+}
+
+// putCurrent puts the current bucket back into the pool, stamped with the current time
+// unless it already has a Timestamp. The caller must hold w.mu.
+func (w *BucketWriter) putCurrent() {
+	if w.current == nil {
+		return
+	}
+	if w.current.Timestamp.IsZero() {
+		w.current.Timestamp = time.Now()
+	}
+	w.pool.Put(*w.current)
+	w.current = nil
+}
+
+// Seal builds the tree of the pool of the writer, records the seal in the write-ahead log
+// if there is one, and continues writing to the empty pool @next.
+// If recording the seal fails, the tree is kept and writes fail with ErrPoolExhausted
+// until Seal is called again, which retries recording the kept tree.
+func (w *BucketWriter) Seal(next *BucketPool) (*MerkleTree, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	tree := w.sealed
+	if tree == nil {
+		w.putCurrent()
+		var err error
+		if tree, err = MakeTree(w.pool); err != nil {
+			return nil, err
+		}
+	}
+	if w.wal != nil {
+		if err := w.wal.LogSeal(tree.MerkleRoot); err != nil {
+			// The pool is drained into the tree, which is kept to retry.
+			w.sealed = tree
+			return nil, err
+		}
+	}
+	w.sealed = nil
+	w.pool = next
+	w.written = false
+	return tree, nil
+}
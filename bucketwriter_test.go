@@ -0,0 +1,184 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestBucketWriter_Spill(t *testing.T) {
+	// Buckets of 24 bytes hold one item of 10 bytes, or two items of 4 bytes.
+	w := NewBucketWriter(NewBucketPool(3, 24, "trades"))
+	items := []string{"0123456789", "abcd", "efgh", "ijkl"}
+	for _, item := range items {
+		if err := w.Write([]byte(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write([]byte("0123456789")); err != ErrPoolExhausted {
+		t.Errorf("error: expected ErrPoolExhausted got %v", err)
+	}
+	if err := w.Write(make([]byte, 17)); err != ErrItemTooLarge {
+		t.Errorf("error: expected ErrItemTooLarge got %v", err)
+	}
+	w.Flush()
+	tree, err := MakeTree(w.Pool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The pool rotates its buckets, so the buckets are compared regardless of their order.
+	expected := map[string]bool{"0123456789": true, "abcd,efgh": true, "ijkl": true}
+	for i, leaf := range tree.Leafs[:3] {
+		sb, _ := asStorageBucket(leaf.C)
+		content, err := sb.ReadContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		joined := string(bytes.Join(content, []byte(",")))
+		if !expected[joined] {
+			t.Errorf("[bucket:%d] error: unexpected bucket content %s", i, joined)
+		}
+		delete(expected, joined)
+	}
+}
+
+func TestBucketWriter_Concurrent(t *testing.T) {
+	const writers, perWriter = 8, 100
+	w := NewBucketWriter(NewBucketPool(200, 64, "trades"))
+	var wg sync.WaitGroup
+	for n := 0; n < writers; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := w.Write([]byte(fmt.Sprintf("%d:%03d", n, i))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+	w.Flush()
+	tree, err := MakeTree(w.Pool())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every item is read back whole, and the items of each writer are in order.
+	next := make([]int, writers)
+	for _, leaf := range tree.Leafs {
+		if leaf.Dup {
+			continue
+		}
+		sb, _ := asStorageBucket(leaf.C)
+		content, err := sb.ReadContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range content {
+			var n, i int
+			if _, err := fmt.Sscanf(string(item), "%d:%d", &n, &i); err != nil {
+				t.Fatalf("error: split or corrupted item %q", item)
+			}
+			if i != next[n] {
+				t.Errorf("[writer:%d] error: expected item %d got %d", n, next[n], i)
+			}
+			next[n] = i + 1
+		}
+	}
+	for n, i := range next {
+		if i != perWriter {
+			t.Errorf("[writer:%d] error: expected %d items got %d", n, perWriter, i)
+		}
+	}
+	if !bytes.Equal(tree.MerkleRoot, tree.Root.Hash) {
+		t.Errorf("error: inconsistent root")
+	}
+}
+
+func TestBucketWriter_ConcurrentSeal(t *testing.T) {
+	const writers, perWriter = 4, 50
+	w := NewBucketWriter(NewBucketPool(400, 32, "trades"))
+	var wg sync.WaitGroup
+	for n := 0; n < writers; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := w.Write([]byte(fmt.Sprintf("%d:%03d", n, i))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(n)
+	}
+	var trees []*MerkleTree
+	for i := 0; i < 5; i++ {
+		tree, err := w.Seal(NewBucketPool(400, 32, "trades"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		trees = append(trees, tree)
+	}
+	wg.Wait()
+	tree, err := w.Seal(NewBucketPool(1, 64, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees = append(trees, tree)
+
+	var items int
+	for _, tree := range trees {
+		for _, leaf := range tree.Leafs {
+			if leaf.Dup {
+				continue
+			}
+			sb, _ := asStorageBucket(leaf.C)
+			content, err := sb.ReadContent()
+			if err != nil {
+				t.Fatal(err)
+			}
+			items += len(content)
+		}
+	}
+	if items != writers*perWriter {
+		t.Errorf("error: expected %d items in sealed trees got %d", writers*perWriter, items)
+	}
+	// The size of an item is checked against the pool it is written to.
+	if err := w.Write(make([]byte, 40)); err != nil {
+		t.Errorf("error: expected item to fit into bucket of new pool got %v", err)
+	}
+}
+
+func TestBucketWriter_Timestamp(t *testing.T) {
+	bp := NewBucketPool(2, 24, "trades")
+	b, err := bp.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.WriteContent([]byte("abcd"))
+	bp.Put(b)
+	if put := <-bp.c; !put.Timestamp.IsZero() {
+		t.Errorf("error: expected Put to keep the zero Timestamp")
+	}
+
+	w := NewBucketWriter(NewBucketPool(2, 24, "trades"))
+	if err := w.Write([]byte("abcd")); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	tree, err := MakeTree(w.Pool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, leaf := range tree.Leafs {
+		sb, _ := asStorageBucket(leaf.C)
+		content, err := sb.ReadContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(content) > 0 && sb.Timestamp.IsZero() {
+			t.Errorf("[bucket:%d] error: expected written bucket to be stamped", i)
+		}
+	}
+}
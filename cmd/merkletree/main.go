@@ -0,0 +1,417 @@
+// Command merkletree builds, inspects and verifies Merkle trees from the command line.
+//
+// Usage:
+//
+//	merkletree build [-lines] [-hash sha256] [-format json|binary] [-o tree] files...
+//	merkletree proof -tree tree (-item text | file)
+//	merkletree verify -root hex -proof proof.json (-item text | file)
+//	merkletree inspect -tree tree
+//
+// Each file, or each line of the files with -lines, is an item of the tree. An item's
+// leaf hash is its hash under the chosen hash strategy of the merkletree package.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cbergoon/merkletree"
+)
+
+// binaryMagic starts the binary encoding of a tree.
+const binaryMagic = "MKT1"
+
+// Proof is the JSON encoding of the Merkle path of an item.
+type Proof struct {
+	HashStrategy string   `json:"hashStrategy"`
+	Root         string   `json:"root"`
+	Leaf         string   `json:"leaf"`
+	Path         []string `json:"path"`
+	Index        []int64  `json:"index"`
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "merkletree:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the subcommand given by @args and writes its output to @out.
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: merkletree build|proof|verify|inspect [flags]")
+	}
+	switch args[0] {
+	case "build":
+		return runBuild(args[1:], out)
+	case "proof":
+		return runProof(args[1:], out)
+	case "verify":
+		return runVerify(args[1:], out)
+	case "inspect":
+		return runInspect(args[1:], out)
+	}
+	return fmt.Errorf("unknown command %s", args[0])
+}
+
+// runBuild builds a tree from files and prints its root.
+func runBuild(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	lines := fs.Bool("lines", false, "use each line of the files as an item")
+	hashStrategy := fs.String("hash", "sha256", "hash strategy: "+strings.Join(hashStrategies(), ", "))
+	format := fs.String("format", "json", "tree encoding: json or binary")
+	output := fs.String("o", "", "write the tree to this file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	items, err := readItems(fs.Args(), *lines)
+	if err != nil {
+		return err
+	}
+	tree, err := buildTree(items, *hashStrategy)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		data, err := encodeTree(tree, *format)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(out, hex.EncodeToString(tree.MerkleRoot))
+	return nil
+}
+
+// runProof prints the JSON proof of an item of a tree.
+func runProof(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("proof", flag.ContinueOnError)
+	treeFile := fs.String("tree", "", "tree file written by build")
+	item := fs.String("item", "", "item text, instead of an item file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := readTree(*treeFile)
+	if err != nil {
+		return err
+	}
+	data, err := readItem(*item, fs.Args())
+	if err != nil {
+		return err
+	}
+	leafHash, err := hashItem(data, tree.HashStrategy)
+	if err != nil {
+		return err
+	}
+	path, index, err := tree.GetMerklePath(merkletree.ByteContent{Content: leafHash})
+	if err != nil {
+		return err
+	}
+	if path == nil {
+		return errors.New("item is not in tree")
+	}
+	proof := Proof{
+		HashStrategy: tree.HashStrategy,
+		Root:         hex.EncodeToString(tree.MerkleRoot),
+		Leaf:         hex.EncodeToString(leafHash),
+		Index:        index,
+	}
+	for _, p := range path {
+		proof.Path = append(proof.Path, hex.EncodeToString(p))
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(proof)
+}
+
+// runVerify checks the proof of an item against a root.
+func runVerify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	root := fs.String("root", "", "expected merkle root in hex")
+	proofFile := fs.String("proof", "", "proof file written by proof")
+	item := fs.String("item", "", "item text, instead of an item file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(*proofFile)
+	if err != nil {
+		return err
+	}
+	var proof Proof
+	if err := json.Unmarshal(raw, &proof); err != nil {
+		return err
+	}
+	merkleRoot, err := hex.DecodeString(*root)
+	if err != nil {
+		return fmt.Errorf("invalid root: %v", err)
+	}
+	data, err := readItem(*item, fs.Args())
+	if err != nil {
+		return err
+	}
+	leafHash, err := hashItem(data, proof.HashStrategy)
+	if err != nil {
+		return err
+	}
+	var path [][]byte
+	for _, p := range proof.Path {
+		b, err := hex.DecodeString(p)
+		if err != nil {
+			return fmt.Errorf("invalid proof: %v", err)
+		}
+		path = append(path, b)
+	}
+	ok, err := merkletree.VerifyMerklePath(leafHash, merkleRoot, path, proof.Index, proof.HashStrategy)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("proof is invalid")
+	}
+	fmt.Fprintln(out, "ok")
+	return nil
+}
+
+// runInspect prints the structure of a tree.
+func runInspect(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	treeFile := fs.String("tree", "", "tree file written by build")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tree, err := readTree(*treeFile)
+	if err != nil {
+		return err
+	}
+	ok, err := tree.VerifyTree()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "hash strategy: %s\n", tree.HashStrategy)
+	fmt.Fprintf(out, "root:          %s\n", hex.EncodeToString(tree.MerkleRoot))
+	fmt.Fprintf(out, "leafs:         %d\n", len(tree.Leafs))
+	fmt.Fprintf(out, "nodes:         %d\n", merkletree.NumNodes(tree.Root))
+	fmt.Fprintf(out, "valid:         %t\n", ok)
+	printNode(out, tree.Root, 0)
+	return nil
+}
+
+// printNode prints @n and its children indented by @depth.
+func printNode(out io.Writer, n *merkletree.Node, depth int) {
+	if n == nil {
+		return
+	}
+	children := n.Children
+	if children == nil && (n.Left != nil || n.Right != nil) {
+		children = []*merkletree.Node{n.Left, n.Right}
+	}
+	kind := "node"
+	if children == nil {
+		kind = "leaf"
+		if n.Dup {
+			kind = "dup"
+		}
+	}
+	fmt.Fprintf(out, "%s%s %s\n", strings.Repeat("  ", depth), kind, hex.EncodeToString(n.Hash))
+	for _, c := range children {
+		printNode(out, c, depth+1)
+	}
+}
+
+// hashStrategies returns the sorted names of the available hash strategies.
+func hashStrategies() []string {
+	var names []string
+	for name := range merkletree.GetHashStrategies() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hashItem returns the leaf hash of @data under @hashStrategy.
+func hashItem(data []byte, hashStrategy string) ([]byte, error) {
+	h, ok := merkletree.GetHashStrategies()[hashStrategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash strategy %s", hashStrategy)
+	}
+	if _, err := h.Write(data); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// buildTree builds a tree whose leafs are the hashes of @items.
+func buildTree(items [][]byte, hashStrategy string) (*merkletree.MerkleTree, error) {
+	leafHashes := make([][]byte, len(items))
+	for i, item := range items {
+		var err error
+		if leafHashes[i], err = hashItem(item, hashStrategy); err != nil {
+			return nil, err
+		}
+	}
+	return treeFromLeafHashes(leafHashes, hashStrategy)
+}
+
+// treeFromLeafHashes builds a tree with the leaf hashes @leafHashes.
+func treeFromLeafHashes(leafHashes [][]byte, hashStrategy string) (*merkletree.MerkleTree, error) {
+	var cs []merkletree.Content
+	for _, h := range leafHashes {
+		cs = append(cs, merkletree.ByteContent{Content: h})
+	}
+	return merkletree.NewTreeWithHashStrategy(cs, hashStrategy)
+}
+
+// leafHashes returns the leaf hashes of @tree without the duplicate of the last leaf.
+func leafHashes(tree *merkletree.MerkleTree) [][]byte {
+	var hashes [][]byte
+	for _, leaf := range tree.Leafs {
+		if !leaf.Dup {
+			hashes = append(hashes, leaf.Hash)
+		}
+	}
+	return hashes
+}
+
+// readItems reads the items from @files, one per file or one per line.
+func readItems(files []string, lines bool) ([][]byte, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no input files")
+	}
+	var items [][]byte
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !lines {
+			items = append(items, data)
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			items = append(items, append([]byte{}, scanner.Bytes()...))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// readItem returns @text if set and the content of the single file in @files otherwise.
+func readItem(text string, files []string) ([]byte, error) {
+	if text != "" {
+		return []byte(text), nil
+	}
+	if len(files) != 1 {
+		return nil, errors.New("expected -item or a single item file")
+	}
+	return os.ReadFile(files[0])
+}
+
+// encodeTree encodes @tree as JSON or in the binary format: the magic, the hash strategy
+// and the leaf hashes, each prefixed by its length as uvarint.
+func encodeTree(tree *merkletree.MerkleTree, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.Marshal(tree)
+	case "binary":
+		buf := bytes.NewBufferString(binaryMagic)
+		writeBytes(buf, []byte(tree.HashStrategy))
+		hashes := leafHashes(tree)
+		writeUvarint(buf, uint64(len(hashes)))
+		for _, h := range hashes {
+			writeBytes(buf, h)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown format %s", format)
+}
+
+// writeUvarint writes @n as uvarint.
+func writeUvarint(buf *bytes.Buffer, n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], n)])
+}
+
+// writeBytes writes @b prefixed by its length as uvarint.
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+// readBytes reads a byte slice prefixed by its length as uvarint.
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, errors.New("truncated tree file")
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+// readTree reads a tree written by build in either format and rebuilds it, such that
+// proofs can be generated from it.
+func readTree(file string) (*merkletree.MerkleTree, error) {
+	if file == "" {
+		return nil, errors.New("missing -tree")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var hashStrategy string
+	var hashes [][]byte
+	if bytes.HasPrefix(data, []byte(binaryMagic)) {
+		r := bytes.NewReader(data[len(binaryMagic):])
+		name, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		hashStrategy = string(name)
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			h, err := readBytes(r)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, h)
+		}
+	} else {
+		var tree merkletree.MerkleTree
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+		hashStrategy = tree.HashStrategy
+		hashes = leafHashes(&tree)
+		rebuilt, err := treeFromLeafHashes(hashes, hashStrategy)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(rebuilt.MerkleRoot, tree.MerkleRoot) {
+			return nil, errors.New("tree file is corrupt: root does not match leafs")
+		}
+		return rebuilt, nil
+	}
+	return treeFromLeafHashes(hashes, hashStrategy)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cbergoon/merkletree"
+)
+
+func runOutput(t *testing.T, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := run(args, &out)
+	return out.String(), err
+}
+
+func TestRun_BuildProofVerify(t *testing.T) {
+	dir := t.TempDir()
+	items := filepath.Join(dir, "items.txt")
+	if err := os.WriteFile(items, []byte("Hello\nHi\nHey\nHola\nGreetings\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, hashStrategy := range []string{"sha256", "sha256d", "keccak256"} {
+		for _, format := range []string{"json", "binary"} {
+			treeFile := filepath.Join(dir, "tree."+hashStrategy+"."+format)
+			root, err := runOutput(t, "build", "-lines", "-hash", hashStrategy, "-format", format, "-o", treeFile, items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root = strings.TrimSpace(root)
+
+			inspect, err := runOutput(t, "inspect", "-tree", treeFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(inspect, root) || !strings.Contains(inspect, "valid:         true") {
+				t.Errorf("[%s %s] error: unexpected inspect output %s", hashStrategy, format, inspect)
+			}
+
+			proof, err := runOutput(t, "proof", "-tree", treeFile, "-item", "Greetings")
+			if err != nil {
+				t.Fatal(err)
+			}
+			proofFile := filepath.Join(dir, "proof.json")
+			if err := os.WriteFile(proofFile, []byte(proof), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := runOutput(t, "verify", "-root", root, "-proof", proofFile, "-item", "Greetings"); err != nil || out != "ok\n" {
+				t.Errorf("[%s %s] error: expected valid proof, got %q %v", hashStrategy, format, out, err)
+			}
+			if _, err := runOutput(t, "verify", "-root", root, "-proof", proofFile, "-item", "Hola"); err == nil {
+				t.Errorf("[%s %s] error: expected invalid proof for other item", hashStrategy, format)
+			}
+		}
+	}
+
+	if _, err := runOutput(t, "proof", "-tree", filepath.Join(dir, "tree.sha256.json"), "-item", "missing"); err == nil {
+		t.Errorf("error: expected error for item not in tree")
+	}
+	if _, err := runOutput(t, "build", "-hash", "md4", items); err == nil {
+		t.Errorf("error: expected error for unknown hash strategy")
+	}
+	if _, err := runOutput(t, "unknown"); err == nil {
+		t.Errorf("error: expected error for unknown command")
+	}
+}
+
+func TestRun_BuildFiles(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	var cs []merkletree.Content
+	for _, content := range []string{"Hello", "Hi", "Hey"} {
+		file := filepath.Join(dir, content)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+		leafHash, err := hashItem([]byte(content), "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, merkletree.ByteContent{Content: leafHash})
+	}
+	root, err := runOutput(t, append([]string{"build"}, files...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := merkletree.NewTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(root) != hex.EncodeToString(tree.MerkleRoot) {
+		t.Errorf("error: expected root %x got %s", tree.MerkleRoot, root)
+	}
+}
@@ -0,0 +1,263 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// -----------------------------------------------------------------------
+// Directory snapshots
+// -----------------------------------------------------------------------
+
+// PathContent implements Content for a file in a directory snapshot.
+// @Path is the slash-separated path relative to the snapshot root
+// @Mode holds the type and permission bits of the file
+// @Hash is the SHA-256 hash of the file content, or of the target of a symlink
+type PathContent struct {
+	Path string
+	Mode fs.FileMode
+	Hash []byte
+}
+
+// Custom marshaler for PathContent type
+func (pc PathContent) MarshalJSON() ([]byte, error) {
+	type _PathContent PathContent
+	var out = struct {
+		Type string `json:"_type"`
+		_PathContent
+	}{
+		Type:         "PathContent",
+		_PathContent: _PathContent(pc),
+	}
+	return json.Marshal(out)
+}
+
+// CalculateHash calculates the hash of a PathContent binding its path, mode and content
+// hash, such that a proof of the leaf shows the file at the path was part of the snapshot.
+func (pc PathContent) CalculateHash() ([]byte, error) {
+	h := sha256.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(pc.Path)))
+	if _, err := h.Write(buf[:]); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write([]byte(pc.Path)); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(buf[:4], uint32(pc.Mode))
+	if _, err := h.Write(buf[:4]); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(pc.Hash); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Equals returns true if two PathContents are identical, false otherwise
+func (pc PathContent) Equals(other Content) (bool, error) {
+	var o PathContent
+	switch oc := other.(type) {
+	case PathContent:
+		o = oc
+	case *PathContent:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	return pc.Path == o.Path && pc.Mode == o.Mode && bytes.Equal(pc.Hash, o.Hash), nil
+}
+
+// DirSnapshot is a Merkle tree over the files of a directory, ordered by path. The
+// snapshot of an empty directory has no tree, see MerkleRoot.
+type DirSnapshot struct {
+	Entries []PathContent
+	Tree    *MerkleTree
+}
+
+// SnapshotDir walks the directory @root and builds a snapshot of its regular files and
+// symlinks. Directories are only represented by the paths of the files they contain.
+func SnapshotDir(root string) (*DirSnapshot, error) {
+	var entries []PathContent
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hash, err := hashPath(path, d.Type())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, PathContent{
+			Path: filepath.ToSlash(rel),
+			Mode: d.Type() | info.Mode().Perm(),
+			Hash: hash,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewDirSnapshot(entries)
+}
+
+// NewDirSnapshot builds a snapshot from @entries. The entries are copied before they are
+// sorted by path.
+func NewDirSnapshot(entries []PathContent) (*DirSnapshot, error) {
+	entries = append([]PathContent(nil), entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	var cs []Content
+	for i, entry := range entries {
+		if i > 0 && entries[i-1].Path == entry.Path {
+			return nil, errors.New("error: duplicate path in snapshot " + entry.Path)
+		}
+		cs = append(cs, entry)
+	}
+	s := &DirSnapshot{Entries: entries}
+	if len(cs) == 0 {
+		return s, nil
+	}
+	tree, err := NewTree(cs)
+	if err != nil {
+		return nil, err
+	}
+	s.Tree = tree
+	return s, nil
+}
+
+// hashPath returns the SHA-256 hash of the content of the file at @path, or of the link
+// target if @typ is a symlink.
+func hashPath(path string, typ fs.FileMode) ([]byte, error) {
+	h := sha256.New()
+	if typ&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, err
+		}
+		h.Write([]byte(target))
+		return h.Sum(nil), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// MerkleRoot returns the root hash of the snapshot. The root of the snapshot of an empty
+// directory is the SHA-256 hash of no data.
+func (s *DirSnapshot) MerkleRoot() []byte {
+	if s.Tree == nil {
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	}
+	return s.Tree.MerkleRoot
+}
+
+// Entry returns the entry of the file at the slash-separated path @path.
+func (s *DirSnapshot) Entry(path string) (PathContent, bool) {
+	if i, ok := s.find(path); ok {
+		return s.Entries[i], true
+	}
+	return PathContent{}, false
+}
+
+// find returns the index of the entry of the file at @path.
+func (s *DirSnapshot) find(path string) (int, bool) {
+	i := sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].Path >= path
+	})
+	return i, i < len(s.Entries) && s.Entries[i].Path == path
+}
+
+// PathProof proves that a file was part of a snapshot.
+type PathProof struct {
+	Entry      PathContent
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// ProvePath returns the proof of the file at the slash-separated path @path.
+func (s *DirSnapshot) ProvePath(path string) (*PathProof, error) {
+	i, ok := s.find(path)
+	if !ok {
+		return nil, errors.New("error: path not in snapshot " + path)
+	}
+	merklePath, index, err := s.Tree.GetMerklePathAt(i)
+	if err != nil {
+		return nil, err
+	}
+	return &PathProof{
+		Entry:      s.Entries[i],
+		MerklePath: merklePath,
+		Index:      index,
+	}, nil
+}
+
+// VerifyPathProof returns true if @proof proves its entry against the snapshot root @root
+// using the hash strategy @hashStrategy of the snapshot tree.
+func VerifyPathProof(root []byte, proof *PathProof, hashStrategy string) (bool, error) {
+	leafHash, err := proof.Entry.CalculateHash()
+	if err != nil {
+		return false, err
+	}
+	return VerifyMerklePath(leafHash, root, proof.MerklePath, proof.Index, hashStrategy)
+}
+
+// DirDiff lists the paths that differ between two snapshots.
+type DirDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Diff returns the paths added, removed and changed, in content or mode, from the
+// snapshot @from to the snapshot @to.
+func Diff(from, to *DirSnapshot) DirDiff {
+	var diff DirDiff
+	i, j := 0, 0
+	for i < len(from.Entries) || j < len(to.Entries) {
+		switch {
+		case j == len(to.Entries) || i < len(from.Entries) && from.Entries[i].Path < to.Entries[j].Path:
+			diff.Removed = append(diff.Removed, from.Entries[i].Path)
+			i++
+		case i == len(from.Entries) || to.Entries[j].Path < from.Entries[i].Path:
+			diff.Added = append(diff.Added, to.Entries[j].Path)
+			j++
+		default:
+			if equal, _ := from.Entries[i].Equals(to.Entries[j]); !equal {
+				diff.Changed = append(diff.Changed, to.Entries[j].Path)
+			}
+			i++
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,154 @@
+package merkletree
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSnapshotDir_ProvePath(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.txt":       "a",
+		"b/c.txt":     "c",
+		"b/d/e.txt":   "e",
+		"b/d/f.txt":   "e",
+		"g/empty.txt": "",
+	})
+	s, err := SnapshotDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries) != 5 {
+		t.Fatalf("error: expected 5 entries got %d", len(s.Entries))
+	}
+	for _, entry := range s.Entries {
+		proof, err := s.ProvePath(entry.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyPathProof(s.MerkleRoot(), proof, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[path:%s] error: expected valid proof", entry.Path)
+		}
+	}
+
+	// The same content at another path does not verify.
+	proof, err := s.ProvePath("b/d/e.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Entry.Path = "b/d/f.txt"
+	if ok, _ := VerifyPathProof(s.MerkleRoot(), proof, "sha256"); ok {
+		t.Errorf("error: expected proof to be bound to its path")
+	}
+	if _, err := s.ProvePath("missing.txt"); err == nil {
+		t.Errorf("error: expected error for missing path")
+	}
+
+	// Snapshots do not depend on the location of the directory.
+	other := t.TempDir()
+	writeFiles(t, other, map[string]string{
+		"a.txt":       "a",
+		"b/c.txt":     "c",
+		"b/d/e.txt":   "e",
+		"b/d/f.txt":   "e",
+		"g/empty.txt": "",
+	})
+	s2, err := SnapshotDir(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.MerkleRoot(), s2.MerkleRoot()) {
+		t.Errorf("error: expected equal roots for equal directories")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"keep.txt":   "keep",
+		"change.txt": "old",
+		"remove.txt": "remove",
+		"mode.txt":   "mode",
+	})
+	old, err := SnapshotDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{
+		"change.txt":  "new",
+		"sub/add.txt": "add",
+	})
+	if err := os.Remove(filepath.Join(root, "remove.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "mode.txt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := SnapshotDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := Diff(old, updated)
+	expected := DirDiff{
+		Added:   []string{"sub/add.txt"},
+		Removed: []string{"remove.txt"},
+		Changed: []string{"change.txt", "mode.txt"},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("error: expected diff %v got %v", expected, diff)
+	}
+	if diff := Diff(updated, updated); diff.Added != nil || diff.Removed != nil || diff.Changed != nil {
+		t.Errorf("error: expected empty diff got %v", diff)
+	}
+}
+
+func TestNewDirSnapshot(t *testing.T) {
+	entries := []PathContent{{Path: "b.txt"}, {Path: "a.txt"}}
+	s, err := NewDirSnapshot(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].Path != "b.txt" {
+		t.Errorf("error: expected entries of the caller not to be sorted")
+	}
+	if s.Entries[0].Path != "a.txt" {
+		t.Errorf("error: expected snapshot entries to be sorted")
+	}
+
+	empty, err := SnapshotDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewDirSnapshot(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(empty.MerkleRoot()) == 0 || !reflect.DeepEqual(empty.MerkleRoot(), other.MerkleRoot()) {
+		t.Errorf("error: expected equal roots for empty snapshots")
+	}
+	if _, err := empty.ProvePath("a.txt"); err == nil {
+		t.Errorf("error: expected error for path in empty snapshot")
+	}
+	diff := Diff(empty, s)
+	if !reflect.DeepEqual(diff.Added, []string{"a.txt", "b.txt"}) {
+		t.Errorf("error: expected all paths to be added, got %v", diff)
+	}
+}
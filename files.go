@@ -0,0 +1,322 @@
+package merkletree
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/bits"
+	"os"
+)
+
+// -----------------------------------------------------------------------
+// File chunks
+// -----------------------------------------------------------------------
+
+// FileContent implements Content for a chunk of a file.
+// @Offset is the position of the chunk in the file in bytes
+// @Length is the size of the chunk in bytes
+// @Hash is the SHA-256 hash of the chunk data
+type FileContent struct {
+	Offset int64
+	Length int64
+	Hash   []byte
+}
+
+// NewFileContent creates the FileContent of the chunk @data at @offset.
+func NewFileContent(offset int64, data []byte) FileContent {
+	h := sha256.Sum256(data)
+	return FileContent{
+		Offset: offset,
+		Length: int64(len(data)),
+		Hash:   h[:],
+	}
+}
+
+// Custom marshaler for FileContent type
+func (fc FileContent) MarshalJSON() ([]byte, error) {
+	type _FileContent FileContent
+	var out = struct {
+		Type string `json:"_type"`
+		_FileContent
+	}{
+		Type:         "FileContent",
+		_FileContent: _FileContent(fc),
+	}
+	return json.Marshal(out)
+}
+
+// CalculateHash calculates the hash of a FileContent from its position, length and the
+// hash of its data, such that a proof of the chunk also proves where it is in the file.
+func (fc FileContent) CalculateHash() ([]byte, error) {
+	h := sha256.New()
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[:8], uint64(fc.Offset))
+	binary.BigEndian.PutUint64(header[8:], uint64(fc.Length))
+	if _, err := h.Write(header[:]); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(fc.Hash); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Equals returns true if two FileContents are identical, false otherwise
+func (fc FileContent) Equals(other Content) (bool, error) {
+	var o FileContent
+	switch oc := other.(type) {
+	case FileContent:
+		o = oc
+	case *FileContent:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	return fc.Offset == o.Offset && fc.Length == o.Length && bytes.Equal(fc.Hash, o.Hash), nil
+}
+
+// Chunker splits a file into chunks.
+type Chunker struct {
+	split bufio.SplitFunc
+	max   int
+}
+
+// FixedChunker returns a Chunker splitting a file into chunks of @size bytes.
+// The last chunk may be shorter.
+func FixedChunker(size int) Chunker {
+	return Chunker{
+		split: func(data []byte, atEOF bool) (int, []byte, error) {
+			if len(data) >= size {
+				return size, data[:size], nil
+			}
+			if atEOF && len(data) > 0 {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		},
+		max: size,
+	}
+}
+
+// gear is the table of random values of the gear rolling hash, derived from SHA-256
+// so that chunk boundaries are stable across implementations.
+var gear = func() (table [256]uint64) {
+	for i := range table {
+		h := sha256.Sum256([]byte{byte(i)})
+		table[i] = binary.BigEndian.Uint64(h[:8])
+	}
+	return
+}()
+
+// ContentDefinedChunker returns a Chunker splitting a file at positions determined by a
+// gear rolling hash of its content, such that an insertion only changes the chunks around
+// it. Chunks are at least @min and at most @max bytes long and @avg bytes on average.
+// Returns an error unless 0 < @min <= @avg <= @max.
+func ContentDefinedChunker(min, avg, max int) (Chunker, error) {
+	if min <= 0 || avg < min || max < avg {
+		return Chunker{}, errors.New("error: chunk sizes must satisfy 0 < min <= avg <= max")
+	}
+	mask := uint64(1)<<uint(bits.Len(uint(avg))-1) - 1
+	return Chunker{
+		split: func(data []byte, atEOF bool) (int, []byte, error) {
+			if len(data) == 0 {
+				return 0, nil, nil
+			}
+			var hash uint64
+			for i := 0; i < len(data) && i < max; i++ {
+				hash = hash<<1 + gear[data[i]]
+				if i+1 >= min && hash&mask == 0 {
+					return i + 1, data[:i+1], nil
+				}
+			}
+			if len(data) >= max {
+				return max, data[:max], nil
+			}
+			if atEOF {
+				return len(data), data, nil
+			}
+			return 0, nil, nil
+		},
+		max: max,
+	}, nil
+}
+
+// Chunks reads @r until EOF and returns the FileContents of its chunks. An empty input
+// yields a single empty chunk.
+func (c Chunker) Chunks(r io.Reader) ([]FileContent, error) {
+	if c.split == nil || c.max <= 0 {
+		return nil, errors.New("error: invalid chunker")
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), c.max+1)
+	scanner.Split(c.split)
+	var chunks []FileContent
+	var offset int64
+	for scanner.Scan() {
+		chunk := NewFileContent(offset, scanner.Bytes())
+		chunks = append(chunks, chunk)
+		offset += chunk.Length
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, NewFileContent(0, nil))
+	}
+	return chunks, nil
+}
+
+// ChunkedFile is a Merkle tree over the chunks of a file.
+type ChunkedFile struct {
+	Size   int64
+	Chunks []FileContent
+	Tree   *MerkleTree
+}
+
+// NewChunkedFile reads @r until EOF, splits it with @c and builds a tree over the chunks.
+func NewChunkedFile(r io.Reader, c Chunker) (*ChunkedFile, error) {
+	chunks, err := c.Chunks(r)
+	if err != nil {
+		return nil, err
+	}
+	var cs []Content
+	var size int64
+	for _, chunk := range chunks {
+		cs = append(cs, chunk)
+		size += chunk.Length
+	}
+	tree, err := NewTree(cs)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkedFile{
+		Size:   size,
+		Chunks: chunks,
+		Tree:   tree,
+	}, nil
+}
+
+// OpenChunkedFile builds a ChunkedFile from the file at @path.
+func OpenChunkedFile(path string, c Chunker) (*ChunkedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewChunkedFile(f, c)
+}
+
+// MerkleRoot returns the root hash of the file.
+func (cf *ChunkedFile) MerkleRoot() []byte {
+	return cf.Tree.MerkleRoot
+}
+
+// ChunkProof is the Merkle path of a single chunk.
+type ChunkProof struct {
+	Chunk      FileContent
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// ChunkRangeProof proves a contiguous range of chunks of a file.
+type ChunkRangeProof struct {
+	Chunks []ChunkProof
+}
+
+// Offset returns the position of the first byte covered by the proof.
+func (p *ChunkRangeProof) Offset() int64 {
+	if len(p.Chunks) == 0 {
+		return 0
+	}
+	return p.Chunks[0].Chunk.Offset
+}
+
+// Length returns the number of bytes covered by the proof.
+func (p *ChunkRangeProof) Length() int64 {
+	var length int64
+	for _, c := range p.Chunks {
+		length += c.Chunk.Length
+	}
+	return length
+}
+
+// ProveRange returns the proof of the chunks overlapping the byte range of @length bytes
+// starting at @offset. The proof covers the bytes from its Offset for its Length.
+func (cf *ChunkedFile) ProveRange(offset, length int64) (*ChunkRangeProof, error) {
+	if offset < 0 || length <= 0 || offset+length > cf.Size {
+		return nil, errors.New("error: range out of file")
+	}
+	proof := &ChunkRangeProof{}
+	for i, chunk := range cf.Chunks {
+		if chunk.Offset+chunk.Length <= offset || chunk.Offset >= offset+length {
+			continue
+		}
+		merklePath, index, err := cf.Tree.GetMerklePathAt(i)
+		if err != nil {
+			return nil, err
+		}
+		proof.Chunks = append(proof.Chunks, ChunkProof{
+			Chunk:      chunk,
+			MerklePath: merklePath,
+			Index:      index,
+		})
+	}
+	return proof, nil
+}
+
+// VerifyChunkRange returns true if @data, the bytes of a file from proof.Offset() for
+// proof.Length(), is proven by @proof to be part of the file with root @root.
+// Returns an error if the chunks of the proof are empty, not contiguous or do not cover @data.
+func VerifyChunkRange(root []byte, data []byte, proof *ChunkRangeProof) (bool, error) {
+	if err := proof.validate(int64(len(data))); err != nil {
+		return false, err
+	}
+	start := proof.Offset()
+	for _, c := range proof.Chunks {
+		from := c.Chunk.Offset - start
+		chunk := NewFileContent(c.Chunk.Offset, data[from:from+c.Chunk.Length])
+		leafHash, err := chunk.CalculateHash()
+		if err != nil {
+			return false, err
+		}
+		ok, err := VerifyMerklePath(leafHash, root, c.MerklePath, c.Index, "sha256")
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// validate returns an error unless the chunks of the proof are non-empty, contiguous and
+// cover exactly @length bytes.
+func (p *ChunkRangeProof) validate(length int64) error {
+	if len(p.Chunks) == 0 {
+		return errors.New("error: chunk range proof without chunks")
+	}
+	offset := p.Offset()
+	var covered int64
+	for _, c := range p.Chunks {
+		if c.Chunk.Length <= 0 {
+			return errors.New("error: chunk range proof with empty chunk")
+		}
+		if c.Chunk.Offset != offset {
+			return errors.New("error: chunks of range proof are not contiguous")
+		}
+		if c.Chunk.Length > length-covered {
+			return errors.New("error: chunks of range proof exceed data")
+		}
+		offset += c.Chunk.Length
+		covered += c.Chunk.Length
+	}
+	if covered != length {
+		return errors.New("error: chunks of range proof do not cover data")
+	}
+	return nil
+}
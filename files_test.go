@@ -0,0 +1,189 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func randomData(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func TestChunker_Fixed(t *testing.T) {
+	tables := []struct {
+		size   int
+		chunks int
+	}{
+		{0, 1},
+		{1, 1},
+		{1024, 1},
+		{1025, 2},
+		{10 * 1024, 10},
+	}
+	for _, table := range tables {
+		chunks, err := FixedChunker(1024).Chunks(bytes.NewReader(randomData(1, table.size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != table.chunks {
+			t.Errorf("[size:%d] error: expected %d chunks got %d", table.size, table.chunks, len(chunks))
+		}
+	}
+}
+
+func TestChunker_ContentDefined(t *testing.T) {
+	data := randomData(2, 256*1024)
+	c, err := ContentDefinedChunker(1024, 4096, 16*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := c.Chunks(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offset int64
+	for i, chunk := range chunks {
+		if chunk.Offset != offset {
+			t.Errorf("[chunk:%d] error: expected offset %d got %d", i, offset, chunk.Offset)
+		}
+		if chunk.Length > 16*1024 || chunk.Length < 1024 && i != len(chunks)-1 {
+			t.Errorf("[chunk:%d] error: chunk length %d out of bounds", i, chunk.Length)
+		}
+		offset += chunk.Length
+	}
+	if offset != int64(len(data)) {
+		t.Errorf("error: chunks cover %d bytes, expected %d", offset, len(data))
+	}
+
+	// Inserting data at the front only changes the first chunks.
+	shifted, err := c.Chunks(bytes.NewReader(append([]byte("inserted"), data...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := make(map[string]bool)
+	for _, chunk := range chunks {
+		hashes[string(chunk.Hash)] = true
+	}
+	var shared int
+	for _, chunk := range shifted {
+		if hashes[string(chunk.Hash)] {
+			shared++
+		}
+	}
+	if shared < len(chunks)-2 {
+		t.Errorf("error: expected at most 2 changed chunks, only %d of %d are shared", shared, len(chunks))
+	}
+}
+
+func TestChunker_ContentDefinedSizes(t *testing.T) {
+	for _, sizes := range [][3]int{{0, 4096, 8192}, {1024, 0, 8192}, {1024, 512, 8192}, {8192, 4096, 1024}, {1024, 4096, 2048}} {
+		if _, err := ContentDefinedChunker(sizes[0], sizes[1], sizes[2]); err == nil {
+			t.Errorf("[sizes:%v] error: expected error for invalid chunk sizes", sizes)
+		}
+	}
+}
+
+func TestChunkedFile_ProveRange(t *testing.T) {
+	data := randomData(3, 100*1000)
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cdc, err := ContentDefinedChunker(512, 2048, 8192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []Chunker{FixedChunker(4096), cdc} {
+		cf, err := OpenChunkedFile(path, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cf.Size != int64(len(data)) {
+			t.Errorf("error: expected size %d got %d", len(data), cf.Size)
+		}
+		ranges := [][2]int64{{0, 1}, {5000, 20000}, {int64(len(data)) - 1, 1}, {0, int64(len(data))}}
+		for _, r := range ranges {
+			proof, err := cf.ProveRange(r[0], r[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proof.Offset() > r[0] || proof.Offset()+proof.Length() < r[0]+r[1] {
+				t.Errorf("[range:%v] error: proof does not cover range", r)
+			}
+			segment := data[proof.Offset() : proof.Offset()+proof.Length()]
+			ok, err := VerifyChunkRange(cf.MerkleRoot(), segment, proof)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[range:%v] error: expected valid range proof", r)
+			}
+			tampered := append([]byte{}, segment...)
+			tampered[len(tampered)/2] ^= 1
+			if ok, _ := VerifyChunkRange(cf.MerkleRoot(), tampered, proof); ok {
+				t.Errorf("[range:%v] error: expected invalid proof for tampered data", r)
+			}
+		}
+		if _, err := cf.ProveRange(0, int64(len(data))+1); err == nil {
+			t.Errorf("error: expected error for range out of file")
+		}
+	}
+}
+
+func TestVerifyChunkRange_Malformed(t *testing.T) {
+	data := []byte("01234")
+	chunk := func(offset, length int64) ChunkProof {
+		return ChunkProof{Chunk: FileContent{Offset: offset, Length: length}}
+	}
+	for i, proof := range []*ChunkRangeProof{
+		// no chunks
+		{},
+		// negative length
+		{Chunks: []ChunkProof{chunk(0, -5), chunk(-5, 10)}},
+		// zero length
+		{Chunks: []ChunkProof{chunk(0, 0), chunk(0, 5)}},
+		// gap between chunks
+		{Chunks: []ChunkProof{chunk(0, 2), chunk(3, 3)}},
+		// overlapping chunks
+		{Chunks: []ChunkProof{chunk(0, 3), chunk(2, 2)}},
+		// lengths sum to more than the data
+		{Chunks: []ChunkProof{chunk(0, 3), chunk(3, 3)}},
+		// lengths sum to less than the data
+		{Chunks: []ChunkProof{chunk(0, 2), chunk(2, 2)}},
+	} {
+		ok, err := VerifyChunkRange(nil, data, proof)
+		if ok || err == nil {
+			t.Errorf("[case:%d] error: expected error for malformed proof, got %v, %v", i, ok, err)
+		}
+	}
+}
+
+func TestFileContent_JSON(t *testing.T) {
+	cf, err := NewChunkedFile(bytes.NewReader(randomData(4, 5000)), FixedChunker(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(cf.Tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree MerkleTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	for i, leaf := range tree.Leafs {
+		ok, err := cf.Tree.Leafs[i].C.Equals(leaf.C)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[leaf:%d] error: expected unmarshaled chunk to equal %v", i, cf.Tree.Leafs[i].C)
+		}
+	}
+}
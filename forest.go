@@ -0,0 +1,103 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Forest is a Merkle tree made from the root hashes of its member trees, see ForestToTree.
+// In contrast to ForestToTree it keeps the member trees, so that any content of a member
+// tree can be proven against the root of the forest.
+type Forest struct {
+	Trees []*MerkleTree
+	Tree  *MerkleTree
+}
+
+// NewForest creates a new Forest from the trees @trees.
+// All trees must use the hash strategy of the forest tree, which is the default strategy.
+func NewForest(trees []*MerkleTree) (*Forest, error) {
+	if len(trees) == 0 {
+		return nil, errors.New("error: cannot construct forest with no trees")
+	}
+	var values []MerkleTree
+	for _, tree := range trees {
+		if tree == nil || tree.Isempty() {
+			return nil, errors.New("error: cannot construct forest with empty tree")
+		}
+		values = append(values, *tree)
+	}
+	forestTree, err := ForestToTree(values)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		if tree.HashStrategy != forestTree.HashStrategy {
+			return nil, errors.New("error: hash strategy of tree differs from forest")
+		}
+	}
+	return &Forest{
+		Trees: trees,
+		Tree:  forestTree,
+	}, nil
+}
+
+// MerkleRoot returns the root hash of the forest.
+func (f *Forest) MerkleRoot() []byte {
+	return f.Tree.MerkleRoot
+}
+
+// Rebuild recomputes the forest tree from the current roots of its member trees.
+// It has to be called after a member tree was changed, e.g. by ExtendTree.
+func (f *Forest) Rebuild() error {
+	forest, err := NewForest(f.Trees)
+	if err != nil {
+		return err
+	}
+	f.Tree = forest.Tree
+	return nil
+}
+
+// GetForestPath gets the Merkle path and indexes of @content from its leaf up to the
+// root of the forest. The path is the concatenation of the path of @content in its member
+// tree and the path of that tree's root in the forest tree, such that it can be checked
+// with VerifyMerklePath against MerkleRoot. Returns nil slices if @content is not in the forest.
+func (f *Forest) GetForestPath(content Content) ([][]byte, []int64, error) {
+	for i := range f.Trees {
+		merklePath, index, err := f.getTreePath(i, content)
+		if err != nil {
+			return nil, nil, err
+		}
+		if merklePath != nil {
+			return merklePath, index, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// getTreePath gets the forest path of @content in the member tree with index @i. The
+// path of the tree root is taken by the index of the tree, as trees may share a root.
+func (f *Forest) getTreePath(i int, content Content) ([][]byte, []int64, error) {
+	tree := f.Trees[i]
+	treePath, treeIndex, err := tree.GetMerklePath(content)
+	if err != nil || treePath == nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(f.Tree.Leafs[i].Hash, tree.MerkleRoot) {
+		return nil, nil, errors.New("error: tree root not in forest, forest needs rebuild")
+	}
+	forestPath, forestIndex, err := f.Tree.GetMerklePathAt(i)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(treePath, forestPath...), append(treeIndex, forestIndex...), nil
+}
+
+// VerifyForestContent returns true if @content is proven by @merklePath and @index to be
+// part of the forest with root @forestRoot, using the hash strategy @hashStrategy of the forest.
+func VerifyForestContent(content Content, forestRoot []byte, merklePath [][]byte, index []int64, hashStrategy string) (bool, error) {
+	leafHash, err := content.CalculateHash()
+	if err != nil {
+		return false, err
+	}
+	return VerifyMerklePath(leafHash, forestRoot, merklePath, index, hashStrategy)
+}
@@ -0,0 +1,150 @@
+package merkletree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func makeStorageTree(t *testing.T, topic string, items ...string) *MerkleTree {
+	bp := NewBucketPool(uint64(len(items)), 64, topic)
+	for i := 0; i < len(items); i++ {
+		bucket, err := bp.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bucket.ID = topic + items[i]
+		bucket.WriteContent([]byte(items[i]))
+		bp.Put(bucket)
+	}
+	tree, err := MakeTree(bp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestForest_GetForestPath(t *testing.T) {
+	trees := []*MerkleTree{
+		makeStorageTree(t, "trades", "a", "b", "c"),
+		makeStorageTree(t, "rates", "d"),
+		makeStorageTree(t, "quotes", "e", "f", "g", "h", "i"),
+	}
+	forest, err := NewForest(trees)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values []MerkleTree
+	for _, tree := range trees {
+		values = append(values, *tree)
+	}
+	forestTree, err := ForestToTree(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(forest.MerkleRoot(), forestTree.MerkleRoot) {
+		t.Errorf("error: expected forest root %v got %v", forestTree.MerkleRoot, forest.MerkleRoot())
+	}
+
+	for i, tree := range trees {
+		for j, leaf := range tree.Leafs {
+			path, index, err := forest.GetForestPath(leaf.C)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := VerifyForestContent(leaf.C, forest.MerkleRoot(), path, index, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[tree:%d leaf:%d] error: expected valid forest path", i, j)
+			}
+			ok, err = VerifyForestContent(leaf.C, tree.MerkleRoot, path, index, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				t.Errorf("[tree:%d leaf:%d] error: expected forest path to be invalid for tree root", i, j)
+			}
+		}
+	}
+
+	path, index, err := forest.GetForestPath(StorageBucket{Content: []byte("not in forest")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != nil || index != nil {
+		t.Errorf("error: expected no path for content not in forest, got %v", path)
+	}
+}
+
+func TestForest_Rebuild(t *testing.T) {
+	trees := []*MerkleTree{
+		makeStorageTree(t, "trades", "a", "b"),
+		makeStorageTree(t, "rates", "c", "d"),
+	}
+	forest, err := NewForest(trees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra := StorageBucket{Content: []byte("e"), Topic: "rates", ID: "e"}
+	if err := trees[1].ExtendTree([]Content{extra}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := forest.GetForestPath(extra); err == nil {
+		t.Errorf("error: expected error for stale forest")
+	}
+	if err := forest.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+	path, index, err := forest.GetForestPath(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := VerifyForestContent(extra, forest.MerkleRoot(), path, index, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("error: expected valid forest path after rebuild")
+	}
+}
+
+func TestForest_EqualRoots(t *testing.T) {
+	var trees []*MerkleTree
+	for _, items := range []string{"ab", "c", "ab"} {
+		var cs []Content
+		for _, item := range items {
+			cs = append(cs, ByteContent{Content: []byte{byte(item)}})
+		}
+		tree, err := NewTree(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trees = append(trees, tree)
+	}
+	forest, err := NewForest(trees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := trees[2].Leafs[0].C
+	for i, tree := range []int{0, 2} {
+		path, index, err := forest.getTreePath(tree, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyForestContent(content, forest.MerkleRoot(), path, index, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid forest path", i)
+		}
+		// The forest part of the path leads to the root of the tree with index @tree.
+		treePath, _, _ := trees[tree].GetMerklePathAt(0)
+		forestIndex := index[len(treePath):]
+		if pos := leafPosition(forestIndex); pos != uint64(tree) {
+			t.Errorf("[case:%d] error: expected path of tree %d got %d", i, tree, pos)
+		}
+	}
+}
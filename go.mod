@@ -1,12 +1,17 @@
 module github.com/cbergoon/merkletree
 
-go 1.14
+go 1.18
 
 require (
-	github.com/kr/pretty v0.2.0 // indirect
 	github.com/sirupsen/logrus v1.6.0
+	golang.org/x/crypto v0.17.0
+	golang.org/x/mod v0.14.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/konsorten/go-windows-terminal-sequences v1.0.3 // indirect
 	github.com/stretchr/testify v1.4.0 // indirect
-	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
@@ -0,0 +1,476 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------
+// InfluxDB line protocol
+// -----------------------------------------------------------------------
+
+// StorageBuckets, tree roots and bucket proofs are stored in InfluxDB as points of the
+// line protocol, one point per line:
+//
+//	buckets,topic=<Topic>,id=<ID> content="<base64 Content>",size=<Size>u,hash_version=<HashVersion>u <Timestamp>
+//	roots,topic=<Topic> root="<hex root>",hash_strategy="<HashStrategy>",leafs=<Leafs>i <Timestamp>
+//	proofs,topic=<Topic>,id=<ID> root="<hex root>",leaf="<hex leaf hash>",path="<hex hashes>",index="<indexes>" <Timestamp>
+//
+// Timestamps are in nanoseconds and omitted for the zero time, as is the id tag if it is
+// empty. The hashes of a Merkle path and its indexes are separated by commas.
+
+const (
+	// BucketMeasurement is the measurement of StorageBucket points.
+	BucketMeasurement = "buckets"
+	// RootMeasurement is the measurement of RootRecord points.
+	RootMeasurement = "roots"
+	// ProofMeasurement is the measurement of ProofRecord points.
+	ProofMeasurement = "proofs"
+)
+
+// MarshalLine returns the StorageBucket as point of the line protocol.
+func (sb StorageBucket) MarshalLine() ([]byte, error) {
+	p := linePoint{
+		measurement: BucketMeasurement,
+		timestamp:   sb.Timestamp,
+	}
+	p.addTag("topic", sb.Topic)
+	p.addTag("id", sb.ID)
+	p.addField("content", quoteField(base64.StdEncoding.EncodeToString(sb.Content)))
+	p.addField("size", strconv.FormatUint(sb.Size, 10)+"u")
+	p.addField("hash_version", strconv.FormatUint(uint64(sb.HashVersion), 10)+"u")
+	return p.marshal()
+}
+
+// UnmarshalLine sets the StorageBucket to the point of the line protocol @line.
+func (sb *StorageBucket) UnmarshalLine(line []byte) error {
+	p, err := parseLine(line, BucketMeasurement)
+	if err != nil {
+		return err
+	}
+	var out StorageBucket
+	out.Topic = p.tags["topic"]
+	out.ID = p.tags["id"]
+	out.Timestamp = p.timestamp
+	content, err := p.stringField("content")
+	if err != nil {
+		return err
+	}
+	if out.Content, err = base64.StdEncoding.DecodeString(content); err != nil {
+		return fmt.Errorf("line error. invalid content: %v", err)
+	}
+	if out.Size, err = p.uintField("size", 64); err != nil {
+		return err
+	}
+	version, err := p.uintField("hash_version", 8)
+	if err != nil {
+		return err
+	}
+	out.HashVersion = HashVersion(version)
+	*sb = out
+	return nil
+}
+
+// RootRecord is the root of the tree of a topic, as stored in InfluxDB. Leafs does not
+// count the duplicate of the last leaf, see MerkleTree.LeafCount.
+type RootRecord struct {
+	Topic        string
+	Root         []byte
+	HashStrategy string
+	Leafs        int
+	Timestamp    time.Time
+}
+
+// NewRootRecord returns the RootRecord of the tree @tree of the topic @topic sealed at @ts.
+func NewRootRecord(topic string, tree *MerkleTree, ts time.Time) RootRecord {
+	return RootRecord{
+		Topic:        topic,
+		Root:         tree.MerkleRoot,
+		HashStrategy: tree.HashStrategy,
+		Leafs:        tree.LeafCount(),
+		Timestamp:    ts,
+	}
+}
+
+// MarshalLine returns the RootRecord as point of the line protocol.
+func (rr RootRecord) MarshalLine() ([]byte, error) {
+	p := linePoint{
+		measurement: RootMeasurement,
+		timestamp:   rr.Timestamp,
+	}
+	p.addTag("topic", rr.Topic)
+	p.addField("root", quoteField(hex.EncodeToString(rr.Root)))
+	p.addField("hash_strategy", quoteField(rr.HashStrategy))
+	p.addField("leafs", strconv.Itoa(rr.Leafs)+"i")
+	return p.marshal()
+}
+
+// UnmarshalLine sets the RootRecord to the point of the line protocol @line.
+func (rr *RootRecord) UnmarshalLine(line []byte) error {
+	p, err := parseLine(line, RootMeasurement)
+	if err != nil {
+		return err
+	}
+	out := RootRecord{Topic: p.tags["topic"], Timestamp: p.timestamp}
+	if out.Root, err = p.hexField("root"); err != nil {
+		return err
+	}
+	if out.HashStrategy, err = p.stringField("hash_strategy"); err != nil {
+		return err
+	}
+	leafs, err := p.intField("leafs")
+	if err != nil {
+		return err
+	}
+	out.Leafs = int(leafs)
+	*rr = out
+	return nil
+}
+
+// ProofRecord is the proof of a StorageBucket against the root of the tree of its topic,
+// as stored in InfluxDB.
+type ProofRecord struct {
+	Topic      string
+	ID         string
+	Root       []byte
+	Leaf       []byte
+	MerklePath [][]byte
+	Index      []int64
+	Timestamp  time.Time
+}
+
+// NewProofRecord returns the ProofRecord of the StorageBucket @sb in the tree @tree.
+func NewProofRecord(tree *MerkleTree, sb StorageBucket) (ProofRecord, error) {
+	leaf, err := sb.CalculateHash()
+	if err != nil {
+		return ProofRecord{}, err
+	}
+	merklePath, index, err := tree.GetMerklePath(sb)
+	if err != nil {
+		return ProofRecord{}, err
+	}
+	if merklePath == nil {
+		return ProofRecord{}, errors.New("error: bucket not in tree")
+	}
+	return ProofRecord{
+		Topic:      sb.Topic,
+		ID:         sb.ID,
+		Root:       tree.MerkleRoot,
+		Leaf:       leaf,
+		MerklePath: merklePath,
+		Index:      index,
+		Timestamp:  sb.Timestamp,
+	}, nil
+}
+
+// Verify returns true if the proof leads from its leaf to its root.
+func (pr ProofRecord) Verify(hashStrategy string) (bool, error) {
+	return VerifyMerklePath(pr.Leaf, pr.Root, pr.MerklePath, pr.Index, hashStrategy)
+}
+
+// MarshalLine returns the ProofRecord as point of the line protocol.
+func (pr ProofRecord) MarshalLine() ([]byte, error) {
+	p := linePoint{
+		measurement: ProofMeasurement,
+		timestamp:   pr.Timestamp,
+	}
+	p.addTag("topic", pr.Topic)
+	p.addTag("id", pr.ID)
+	p.addField("root", quoteField(hex.EncodeToString(pr.Root)))
+	p.addField("leaf", quoteField(hex.EncodeToString(pr.Leaf)))
+	path := make([]string, len(pr.MerklePath))
+	for i, hash := range pr.MerklePath {
+		path[i] = hex.EncodeToString(hash)
+	}
+	p.addField("path", quoteField(strings.Join(path, ",")))
+	index := make([]string, len(pr.Index))
+	for i, idx := range pr.Index {
+		index[i] = strconv.FormatInt(idx, 10)
+	}
+	p.addField("index", quoteField(strings.Join(index, ",")))
+	return p.marshal()
+}
+
+// UnmarshalLine sets the ProofRecord to the point of the line protocol @line.
+func (pr *ProofRecord) UnmarshalLine(line []byte) error {
+	p, err := parseLine(line, ProofMeasurement)
+	if err != nil {
+		return err
+	}
+	out := ProofRecord{Topic: p.tags["topic"], ID: p.tags["id"], Timestamp: p.timestamp}
+	if out.Root, err = p.hexField("root"); err != nil {
+		return err
+	}
+	if out.Leaf, err = p.hexField("leaf"); err != nil {
+		return err
+	}
+	path, err := p.stringField("path")
+	if err != nil {
+		return err
+	}
+	index, err := p.stringField("index")
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		for _, s := range strings.Split(path, ",") {
+			hash, err := hex.DecodeString(s)
+			if err != nil {
+				return fmt.Errorf("line error. invalid path: %v", err)
+			}
+			out.MerklePath = append(out.MerklePath, hash)
+		}
+	}
+	if index != "" {
+		for _, s := range strings.Split(index, ",") {
+			idx, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return fmt.Errorf("line error. invalid index: %v", err)
+			}
+			out.Index = append(out.Index, idx)
+		}
+	}
+	if len(out.Index) != len(out.MerklePath) {
+		return errors.New("line error. path and index differ in length")
+	}
+	*pr = out
+	return nil
+}
+
+// linePoint is a point of the line protocol. Field values are kept in their encoded form.
+type linePoint struct {
+	measurement string
+	tagKeys     []string
+	tags        map[string]string
+	fieldKeys   []string
+	fields      map[string]string
+	timestamp   time.Time
+}
+
+// addTag adds the tag @key if @value is not empty, which the line protocol does not allow.
+func (p *linePoint) addTag(key, value string) {
+	if value == "" {
+		return
+	}
+	if p.tags == nil {
+		p.tags = make(map[string]string)
+	}
+	p.tagKeys = append(p.tagKeys, key)
+	p.tags[key] = value
+}
+
+// addField adds the field @key with the encoded value @value.
+func (p *linePoint) addField(key, value string) {
+	if p.fields == nil {
+		p.fields = make(map[string]string)
+	}
+	p.fieldKeys = append(p.fieldKeys, key)
+	p.fields[key] = value
+}
+
+// marshal returns the point as line without trailing newline.
+func (p *linePoint) marshal() ([]byte, error) {
+	for _, key := range p.tagKeys {
+		if strings.ContainsAny(p.tags[key], "\r\n") {
+			return nil, errors.New("line error. tag contains a line break " + key)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(escapeLine(p.measurement, ", "))
+	for _, key := range p.tagKeys {
+		buf.WriteByte(',')
+		buf.WriteString(escapeLine(key, ",= "))
+		buf.WriteByte('=')
+		buf.WriteString(escapeLine(p.tags[key], ",= "))
+	}
+	for i, key := range p.fieldKeys {
+		if i == 0 {
+			buf.WriteByte(' ')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(escapeLine(key, ",= "))
+		buf.WriteByte('=')
+		buf.WriteString(p.fields[key])
+	}
+	if !p.timestamp.IsZero() {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(p.timestamp.UnixNano(), 10))
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeLine escapes the characters @special and backslashes in @s.
+func escapeLine(s, special string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\\' || strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// quoteField returns @s as string field value.
+func quoteField(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseLine parses the line @line, which must be a point of the measurement @measurement.
+func parseLine(line []byte, measurement string) (*linePoint, error) {
+	s := strings.TrimRight(string(line), "\r\n")
+	p := &linePoint{tags: make(map[string]string), fields: make(map[string]string)}
+
+	// measurement and tags up to the first unescaped space
+	key, rest, err := scanLine(s, ", ", false)
+	if err != nil {
+		return nil, err
+	}
+	p.measurement = key
+	if p.measurement != measurement {
+		return nil, fmt.Errorf("line error. expected measurement %s got %s", measurement, p.measurement)
+	}
+	for strings.HasPrefix(rest, ",") {
+		var value string
+		if key, rest, err = scanLine(rest[1:], "=", false); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(rest, "=") {
+			return nil, errors.New("line error. tag without value")
+		}
+		if value, rest, err = scanLine(rest[1:], ", ", false); err != nil {
+			return nil, err
+		}
+		p.tags[key] = value
+	}
+
+	// fields
+	if !strings.HasPrefix(rest, " ") {
+		return nil, errors.New("line error. missing fields")
+	}
+	rest = rest[1:]
+	for {
+		var value string
+		if key, rest, err = scanLine(rest, "=", false); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(rest, "=") {
+			return nil, errors.New("line error. field without value")
+		}
+		if value, rest, err = scanLine(rest[1:], ", ", true); err != nil {
+			return nil, err
+		}
+		p.fields[key] = value
+		if !strings.HasPrefix(rest, ",") {
+			break
+		}
+		rest = rest[1:]
+	}
+
+	// timestamp
+	if rest != "" {
+		if !strings.HasPrefix(rest, " ") {
+			return nil, errors.New("line error. invalid field set")
+		}
+		ns, err := strconv.ParseInt(rest[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line error. invalid timestamp: %v", err)
+		}
+		p.timestamp = time.Unix(0, ns).UTC()
+	}
+	return p, nil
+}
+
+// scanLine scans @s up to the first unescaped character of @stop and returns the
+// unescaped token and the rest of @s. If @field is true, the token is a field value
+// which is kept in its encoded form and may be a quoted string.
+func scanLine(s, stop string, field bool) (string, string, error) {
+	if field && strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return s[:i+1], s[i+1:], nil
+			}
+		}
+		return "", "", errors.New("line error. unterminated string")
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && !field && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case strings.IndexByte(stop, s[i]) >= 0:
+			if b.Len() == 0 {
+				return "", "", errors.New("line error. empty key or value")
+			}
+			return b.String(), s[i:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	if b.Len() == 0 {
+		return "", "", errors.New("line error. empty key or value")
+	}
+	return b.String(), "", nil
+}
+
+// stringField returns the string field @key.
+func (p *linePoint) stringField(key string) (string, error) {
+	value, ok := p.fields[key]
+	if !ok {
+		return "", errors.New("line error. missing field " + key)
+	}
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", errors.New("line error. field is not a string " + key)
+	}
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(value[1 : len(value)-1]), nil
+}
+
+// hexField returns the hex encoded string field @key.
+func (p *linePoint) hexField(key string) ([]byte, error) {
+	value, err := p.stringField(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("line error. invalid field %s: %v", key, err)
+	}
+	return b, nil
+}
+
+// uintField returns the unsigned integer field @key of @bits bits.
+func (p *linePoint) uintField(key string, bits int) (uint64, error) {
+	value, ok := p.fields[key]
+	if !ok || !strings.HasSuffix(value, "u") {
+		return 0, errors.New("line error. missing unsigned integer field " + key)
+	}
+	n, err := strconv.ParseUint(strings.TrimSuffix(value, "u"), 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("line error. invalid field %s: %v", key, err)
+	}
+	return n, nil
+}
+
+// intField returns the integer field @key.
+func (p *linePoint) intField(key string) (int64, error) {
+	value, ok := p.fields[key]
+	if !ok || !strings.HasSuffix(value, "i") {
+		return 0, errors.New("line error. missing integer field " + key)
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(value, "i"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line error. invalid field %s: %v", key, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,154 @@
+package merkletree
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// influxBuckets returns the buckets of the golden files.
+func influxBuckets() []StorageBucket {
+	items := [][]string{
+		{"btc-usd 42000.5", "eth-usd 3000"},
+		{"btc-usd 42001"},
+		{`quote "a,b=c"`},
+	}
+	var sbs []StorageBucket
+	for i, bucketItems := range items {
+		b := NewBucket(64, "trades, spot")
+		for _, item := range bucketItems {
+			b.WriteContent([]byte(item))
+		}
+		b.ID = []string{"b1", "b 2", ""}[i]
+		b.Timestamp = time.Date(2021, 3, 4, 5, 6, 7+i, 8, time.UTC)
+		sbs = append(sbs, bucketToStorage(*b))
+	}
+	return sbs
+}
+
+// readGolden returns the lines of the golden file @name.
+func readGolden(t *testing.T, name string) []string {
+	golden, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(golden))
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+func TestInflux_Buckets(t *testing.T) {
+	sbs := influxBuckets()
+	golden := readGolden(t, "buckets.lp")
+	if len(golden) != len(sbs) {
+		t.Fatalf("error: expected %d golden lines got %d", len(sbs), len(golden))
+	}
+	for i, sb := range sbs {
+		line, err := sb.MarshalLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != golden[i] {
+			t.Errorf("[case:%d] error: expected line\n%s\ngot\n%s", i, golden[i], line)
+		}
+		var parsed StorageBucket
+		if err := parsed.UnmarshalLine([]byte(golden[i])); err != nil {
+			t.Fatalf("[case:%d] %v", i, err)
+		}
+		if ok, _ := sb.Equals(parsed); !ok {
+			t.Errorf("[case:%d] error: expected parsed bucket %v got %v", i, sb, parsed)
+		}
+		h1, _ := sb.CalculateHash()
+		h2, _ := parsed.CalculateHash()
+		if !bytes.Equal(h1, h2) {
+			t.Errorf("[case:%d] error: expected parsed bucket to have the same hash", i)
+		}
+	}
+}
+
+func TestInflux_RootsAndProofs(t *testing.T) {
+	sbs := influxBuckets()
+	var cs []Content
+	for _, sb := range sbs {
+		cs = append(cs, sb)
+	}
+	tree, err := NewTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewRootRecord("trades, spot", tree, time.Date(2021, 3, 4, 6, 0, 0, 0, time.UTC))
+	line, err := root.MarshalLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := readGolden(t, "roots.lp")
+	if string(line) != golden[0] {
+		t.Errorf("error: expected line\n%s\ngot\n%s", golden[0], line)
+	}
+	var parsedRoot RootRecord
+	if err := parsedRoot.UnmarshalLine([]byte(golden[0])); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsedRoot.Root, tree.MerkleRoot) || parsedRoot.Leafs != 3 || parsedRoot.HashStrategy != "sha256" ||
+		parsedRoot.Topic != root.Topic || !parsedRoot.Timestamp.Equal(root.Timestamp) {
+		t.Errorf("error: unexpected parsed root %v", parsedRoot)
+	}
+
+	golden = readGolden(t, "proofs.lp")
+	for i, sb := range sbs {
+		proof, err := NewProofRecord(tree, sb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, err := proof.MarshalLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != golden[i] {
+			t.Errorf("[case:%d] error: expected line\n%s\ngot\n%s", i, golden[i], line)
+		}
+		var parsed ProofRecord
+		if err := parsed.UnmarshalLine([]byte(golden[i])); err != nil {
+			t.Fatalf("[case:%d] %v", i, err)
+		}
+		ok, err := parsed.Verify(parsedRoot.HashStrategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || !bytes.Equal(parsed.Root, parsedRoot.Root) || parsed.ID != sb.ID {
+			t.Errorf("[case:%d] error: expected parsed proof to verify against the root", i)
+		}
+	}
+}
+
+func TestInflux_Invalid(t *testing.T) {
+	lines := []string{
+		``,
+		`roots,topic=a root="00",hash_strategy="sha256",leafs=1i`,
+		`buckets,topic=a`,
+		`buckets,topic=a content="AA==",size=1u`,
+		`buckets,topic=a content="AA==,size=1u,hash_version=1u`,
+		`buckets,topic=a content="!",size=1u,hash_version=1u`,
+		`buckets,topic=a content="AA==",size=1i,hash_version=1u`,
+		`buckets,topic=a content="AA==",size=1u,hash_version=256u`,
+		`buckets,topic=a content="AA==",size=1u,hash_version=1u 12x`,
+		`buckets,topic content="AA==",size=1u,hash_version=1u`,
+	}
+	for i, line := range lines {
+		var sb StorageBucket
+		if err := sb.UnmarshalLine([]byte(line)); err == nil {
+			t.Errorf("[case:%d] error: expected error for line %s", i, line)
+		}
+	}
+	if _, err := (StorageBucket{Topic: "a\nb"}).MarshalLine(); err == nil || !strings.Contains(err.Error(), "line break") {
+		t.Errorf("error: expected error for line break in tag")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+
+	"golang.org/x/crypto/sha3"
 )
 
 // newContent is used for the unified marshalling/unmarshalling of data
@@ -17,6 +19,11 @@ import (
 var newContent = map[string]func() Content{
 	"StorageBucket": func() Content { return new(StorageBucket) },
 	"ByteContent":   func() Content { return new(ByteContent) },
+	"FileContent":   func() Content { return new(FileContent) },
+	"PathContent":   func() Content { return new(PathContent) },
+	"SaltedContent": func() Content { return new(SaltedContent) },
+	"RecordContent": func() Content { return new(RecordContent) },
+	"TypedContent":  func() Content { return new(typedContent[json.RawMessage]) },
 }
 
 // Content represents the data that is stored and verified by the tree. A type that
@@ -33,13 +40,20 @@ type MerkleTree struct {
 	MerkleRoot   []byte
 	HashStrategy string
 	Leafs        []*Node
+	// SortPairs orders the hashes of the children of a node before hashing them, see
+	// NewSortedPairTree.
+	SortPairs bool `json:",omitempty"`
+	// Arity is the number of children of an inner node, see NewTreeWithArity. Zero means 2.
+	Arity int `json:",omitempty"`
 }
 
 // GetHashStrategies returns a map which maps the hash strategy name as a string
 // to the corresponding hashing function.
 func GetHashStrategies() map[string]hash.Hash {
 	hashMap := map[string]hash.Hash{
-		"sha256": sha256.New(),
+		"sha256":    sha256.New(),
+		"sha256d":   newDoubleSHA256(),
+		"keccak256": sha3.NewLegacyKeccak256(),
 	}
 	return hashMap
 }
@@ -69,7 +83,19 @@ func (bc ByteContent) CalculateHash() ([]byte, error) {
 
 // Equals returns true if two ByteContents are identical, false otherwise
 func (bc ByteContent) Equals(other Content) (bool, error) {
-	if !bytes.Equal(bc.Content, other.(ByteContent).Content) {
+	var o ByteContent
+	switch oc := other.(type) {
+	case ByteContent:
+		o = oc
+	case *ByteContent:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	if !bytes.Equal(bc.Content, o.Content) {
 		return false, nil
 	}
 	return true, nil
@@ -78,33 +104,38 @@ func (bc ByteContent) Equals(other Content) (bool, error) {
 // Node represents a node, root, or leaf in the tree. It stores pointers to its immediate
 // relationships, a hash, the content stored if it is a leaf, and other metadata.
 type Node struct {
-	Left   *Node
-	Right  *Node
-	Hash   []byte
-	C      Content
-	tree   *MerkleTree
-	parent *Node
-	leaf   bool
-	Dup    bool
+	Left  *Node
+	Right *Node
+	// Children are the children of an inner node of a tree with an arity greater than 2,
+	// whose nodes have no Left and Right.
+	Children []*Node `json:",omitempty"`
+	Hash     []byte
+	C        Content
+	tree     *MerkleTree
+	parent   *Node
+	leaf     bool
+	Dup      bool
 }
 
 // UnmarshalJSON is a custom unmarshaler for nodes
 func (n *Node) UnmarshalJSON(byteData []byte) error {
 	var node struct {
-		Left   *Node
-		Right  *Node
-		Hash   []byte
-		C      json.RawMessage
-		tree   *MerkleTree
-		parent *Node
-		leaf   bool
-		Dup    bool
+		Left     *Node
+		Right    *Node
+		Children []*Node
+		Hash     []byte
+		C        json.RawMessage
+		tree     *MerkleTree
+		parent   *Node
+		leaf     bool
+		Dup      bool
 	}
 	if err := json.Unmarshal(byteData, &node); err != nil {
 		return err
 	}
 	n.Left = node.Left
 	n.Right = node.Right
+	n.Children = node.Children
 	n.Hash = node.Hash
 	n.tree = node.tree
 	n.parent = node.parent
@@ -160,14 +191,50 @@ func (n *Node) UnmarshalJSON(byteData []byte) error {
 // 	return nil
 // }
 
+// concatHashes returns the concatenation of @left and @right in a new slice. Appending to
+// @left directly could write into the backing array of a leaf's content.
+func concatHashes(left, right []byte) []byte {
+	chash := make([]byte, 0, len(left)+len(right))
+	return append(append(chash, left...), right...)
+}
+
+// children returns the children of an inner node.
+func (n *Node) children() []*Node {
+	if n.Children != nil {
+		return n.Children
+	}
+	return []*Node{n.Left, n.Right}
+}
+
 //calculateNodeHash is a helper function that calculates the hash of the node.
 func (n *Node) calculateNodeHash() ([]byte, error) {
 	if n.leaf {
 		return n.C.CalculateHash()
 	}
-	hashMap := GetHashStrategies()
-	h := hashMap[n.tree.HashStrategy]
-	if _, err := h.Write(append(n.Left.Hash, n.Right.Hash...)); err != nil {
+	var hashes [][]byte
+	for _, c := range n.children() {
+		hashes = append(hashes, c.Hash)
+	}
+	return n.tree.nodeHash(hashes...)
+}
+
+// arity returns the number of children of an inner node of the tree.
+func (m *MerkleTree) arity() int {
+	if m.Arity == 0 {
+		return 2
+	}
+	return m.Arity
+}
+
+// nodeHash returns the hash of a node with the child hashes @children using the hash
+// strategy of the tree. The hashes of a pair are concatenated in ascending order if the
+// tree sorts pairs, in the given order otherwise.
+func (m *MerkleTree) nodeHash(children ...[]byte) ([]byte, error) {
+	if m.SortPairs && len(children) == 2 && bytes.Compare(children[0], children[1]) > 0 {
+		children = [][]byte{children[1], children[0]}
+	}
+	h := GetHashStrategies()[m.HashStrategy]
+	if _, err := h.Write(bytes.Join(children, nil)); err != nil {
 		return nil, err
 	}
 	return h.Sum(nil), nil
@@ -215,8 +282,12 @@ func NewTreeWithHashStrategy(cs []Content, hashStrategy string) (*MerkleTree, er
 	return t, nil
 }
 
-// GetMerklePath gets Merkle path and indexes (left leaf or right leaf)
+// GetMerklePath gets Merkle path and indexes (left leaf or right leaf). Trees with an
+// arity greater than 2 have no such path, see GetWideProof.
 func (m *MerkleTree) GetMerklePath(content Content) ([][]byte, []int64, error) {
+	if m.arity() != 2 {
+		return nil, nil, errNotBinary
+	}
 	for _, current := range m.Leafs {
 		ok, err := current.C.Equals(content)
 		if err != nil {
@@ -224,26 +295,77 @@ func (m *MerkleTree) GetMerklePath(content Content) ([][]byte, []int64, error) {
 		}
 
 		if ok {
-			currentParent := current.parent
-			var merklePath [][]byte
-			var index []int64
-			for currentParent != nil {
-				if bytes.Equal(currentParent.Left.Hash, current.Hash) {
-					merklePath = append(merklePath, currentParent.Right.Hash)
-					index = append(index, 1) // right leaf
-				} else {
-					merklePath = append(merklePath, currentParent.Left.Hash)
-					index = append(index, 0) // left leaf
-				}
-				current = currentParent
-				currentParent = currentParent.parent
-			}
+			merklePath, index := current.merklePath()
 			return merklePath, index, nil
 		}
 	}
 	return nil, nil, nil
 }
 
+// GetMerklePathAt gets Merkle path and indexes (left leaf or right leaf) of the leaf at
+// @i, in the format of GetMerklePath. Unlike GetMerklePath it proves the leaf at @i
+// even if an earlier leaf holds equal content. The duplicate of the last leaf is not a
+// leaf of its own and has no path.
+func (m *MerkleTree) GetMerklePathAt(i int) ([][]byte, []int64, error) {
+	if i < 0 || i >= m.LeafCount() {
+		return nil, nil, errors.New("error: leaf index out of range")
+	}
+	if m.arity() != 2 {
+		return nil, nil, errNotBinary
+	}
+	merklePath, index := m.Leafs[i].merklePath()
+	return merklePath, index, nil
+}
+
+// merklePath returns the Merkle path and indexes from the node to the root.
+func (n *Node) merklePath() ([][]byte, []int64) {
+	current := n
+	currentParent := current.parent
+	var merklePath [][]byte
+	var index []int64
+	for currentParent != nil {
+		// Siblings are told apart by identity, as they may have equal hashes.
+		if currentParent.Left == current {
+			merklePath = append(merklePath, currentParent.Right.Hash)
+			index = append(index, 1) // right leaf
+		} else {
+			merklePath = append(merklePath, currentParent.Left.Hash)
+			index = append(index, 0) // left leaf
+		}
+		current = currentParent
+		currentParent = currentParent.parent
+	}
+	return merklePath, index
+}
+
+// VerifyMerklePath returns true if hashing @leafHash along @merklePath, as returned by
+// GetMerklePath, with the hash strategy @hashStrategy yields @merkleRoot.
+// An index of 1 means the sibling is the right node, 0 that it is the left node.
+func VerifyMerklePath(leafHash []byte, merkleRoot []byte, merklePath [][]byte, index []int64, hashStrategy string) (bool, error) {
+	if len(merklePath) != len(index) {
+		return false, errors.New("error: merkle path and index differ in length")
+	}
+	h, ok := GetHashStrategies()[hashStrategy]
+	if !ok {
+		return false, fmt.Errorf("error: unknown hash strategy %s", hashStrategy)
+	}
+	hash := leafHash
+	for i, sibling := range merklePath {
+		var chash []byte
+		if index[i] == 1 {
+			chash = append(append(chash, hash...), sibling...)
+		} else {
+			chash = append(append(chash, sibling...), hash...)
+		}
+		h.Reset()
+		if _, err := h.Write(chash); err != nil {
+			return false, err
+		}
+		hash = h.Sum(nil)
+	}
+	return bytes.Equal(hash, merkleRoot), nil
+}
+
 //buildWithContent is a helper function that for a given set of Contents, generates a
 //corresponding tree and returns the root node, a list of leaf nodes, and a possible error.
 //Returns an error if cs contains no Contents.
@@ -264,10 +386,11 @@ func buildWithContent(cs []Content, t *MerkleTree) (*Node, []*Node, error) {
 			tree: t,
 		})
 	}
-	if len(leafs)%2 == 1 {
+	last := leafs[len(leafs)-1]
+	for len(leafs)%t.arity() != 0 {
 		duplicate := &Node{
-			Hash: leafs[len(leafs)-1].Hash,
-			C:    leafs[len(leafs)-1].C,
+			Hash: last.Hash,
+			C:    last.C,
 			leaf: true,
 			Dup:  true,
 			tree: t,
@@ -284,30 +407,40 @@ func buildWithContent(cs []Content, t *MerkleTree) (*Node, []*Node, error) {
 
 //buildIntermediate is a helper function that for a given list of leaf nodes, constructs
 //the intermediate and root levels of the tree. Returns the resulting root node of the tree.
+//An incomplete group of children at the end of a level is filled up by repeating the last node.
 func buildIntermediate(nl []*Node, t *MerkleTree) (*Node, error) {
 	var nodes []*Node
-
-	for i := 0; i < len(nl); i += 2 {
-		hashMap := GetHashStrategies()
-		h := hashMap[t.HashStrategy]
-		var left, right int = i, i + 1
-		if i+1 == len(nl) {
-			right = i
+	arity := t.arity()
+
+	for i := 0; i < len(nl); i += arity {
+		children := make([]*Node, arity)
+		hashes := make([][]byte, arity)
+		for j := range children {
+			k := i + j
+			if k >= len(nl) {
+				k = len(nl) - 1
+			}
+			children[j] = nl[k]
+			hashes[j] = nl[k].Hash
 		}
-		chash := append(nl[left].Hash, nl[right].Hash...)
-		if _, err := h.Write(chash); err != nil {
+		hash, err := t.nodeHash(hashes...)
+		if err != nil {
 			return nil, err
 		}
 		n := &Node{
-			Left:  nl[left],
-			Right: nl[right],
-			Hash:  h.Sum(nil),
-			tree:  t,
+			Hash: hash,
+			tree: t,
+		}
+		if arity == 2 {
+			n.Left, n.Right = children[0], children[1]
+		} else {
+			n.Children = children
 		}
 		nodes = append(nodes, n)
-		nl[left].parent = n
-		nl[right].parent = n
-		if len(nl) == 2 {
+		for _, c := range children {
+			c.parent = n
+		}
+		if len(nl) <= arity {
 			return n, nil
 		}
 	}
@@ -319,7 +452,9 @@ func buildIntermediate(nl []*Node, t *MerkleTree) (*Node, error) {
 func (m *MerkleTree) RebuildTree() error {
 	var cs []Content
 	for _, c := range m.Leafs {
-		cs = append(cs, c.C)
+		if !c.Dup {
+			cs = append(cs, c.C)
+		}
 	}
 	root, leafs, err := buildWithContent(cs, m)
 	if err != nil {
@@ -365,21 +500,15 @@ func (n *Node) verifyNode() ([]byte, error) {
 	if n.leaf {
 		return n.C.CalculateHash()
 	}
-	rightBytes, err := n.Right.verifyNode()
-	if err != nil {
-		return nil, err
-	}
-
-	leftBytes, err := n.Left.verifyNode()
-	if err != nil {
-		return nil, err
-	}
-	hashMap := GetHashStrategies()
-	h := hashMap[n.tree.HashStrategy]
-	if _, err := h.Write(append(leftBytes, rightBytes...)); err != nil {
-		return nil, err
+	var hashes [][]byte
+	for _, c := range n.children() {
+		hash, err := c.verifyNode()
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
 	}
-	return h.Sum(nil), nil
+	return n.tree.nodeHash(hashes...)
 }
 
 //VerifyTree verify tree validates the hashes at each level of the tree and returns true if the
@@ -410,22 +539,20 @@ func (m *MerkleTree) VerifyContent(content Content) (bool, error) {
 		if ok {
 			currentParent := l.parent
 			for currentParent != nil {
-				hashMap := GetHashStrategies()
-				h := hashMap[m.HashStrategy]
-				rightBytes, err := currentParent.Right.calculateNodeHash()
-				if err != nil {
-					return false, err
+				var hashes [][]byte
+				for _, c := range currentParent.children() {
+					childHash, err := c.calculateNodeHash()
+					if err != nil {
+						return false, err
+					}
+					hashes = append(hashes, childHash)
 				}
 
-				leftBytes, err := currentParent.Left.calculateNodeHash()
+				hash, err := m.nodeHash(hashes...)
 				if err != nil {
 					return false, err
 				}
-
-				if _, err := h.Write(append(leftBytes, rightBytes...)); err != nil {
-					return false, err
-				}
-				if !bytes.Equal(h.Sum(nil), currentParent.Hash) {
+				if !bytes.Equal(hash, currentParent.Hash) {
 					return false, nil
 				}
 				currentParent = currentParent.parent
@@ -456,26 +583,36 @@ func (m *MerkleTree) String() string {
 // Leafs are not counted.
 func NumNodes(node *Node) int {
 	count := 1
-	if node.Left.C == nil {
-		count += NumNodes(node.Left)
-	}
-	if node.Right.C == nil {
-		count += NumNodes(node.Right)
+	for _, c := range node.children() {
+		if c.C == nil {
+			count += NumNodes(c)
+		}
 	}
 	return count
 }
 
+// LeafCount returns the number of leafs of the tree without the duplicates of the last leaf.
+func (m *MerkleTree) LeafCount() int {
+	n := len(m.Leafs)
+	for n > 0 && m.Leafs[n-1].Dup {
+		n--
+	}
+	return n
+}
+
 // Isempty returns true if merkle tree at @m is empty, false otherwise
 func (m *MerkleTree) Isempty() bool {
 	return m.Root == nil
 }
 
 // DataInStorageTree returns true if @data is in a bucket of @tree along with the bucket.
+// See TimeForest.DataInRange for a lookup restricted to a time window.
 func DataInStorageTree(data []byte, tree MerkleTree) (bool, StorageBucket, error) {
-	// oldest date of pool cannot be older than @timestamp as pools are made and stamped after data collection.
-	// First look for first pool after @timestamp.
 	for _, leaf := range tree.Leafs {
-		storageBucket := leaf.C.(StorageBucket)
+		storageBucket, ok := asStorageBucket(leaf.C)
+		if !ok {
+			return false, StorageBucket{}, errors.New("error: tree leaf is not a StorageBucket")
+		}
 		content, err := (&storageBucket).ReadContent()
 		if err != nil {
 			return false, StorageBucket{}, err
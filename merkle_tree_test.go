@@ -425,3 +425,39 @@ func TestMerkleTree_MerklePath(t *testing.T) {
 		}
 	}
 }
+
+func TestMerkleTree_GetMerklePathAt(t *testing.T) {
+	contents := []Content{
+		TestSHA256Content{x: "Hello"},
+		TestSHA256Content{x: "Hi"},
+		TestSHA256Content{x: "Hello"},
+	}
+	tree, err := NewTree(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range contents {
+		merklePath, index, err := tree.GetMerklePathAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyMerklePath(tree.Leafs[i].Hash, tree.MerkleRoot, merklePath, index, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid merkle path", i)
+		}
+	}
+	first, _, _ := tree.GetMerklePathAt(0)
+	third, _, _ := tree.GetMerklePathAt(2)
+	if bytes.Equal(first[0], third[0]) {
+		t.Errorf("error: expected paths of equal content at different leafs to differ")
+	}
+	if _, _, err := tree.GetMerklePathAt(len(tree.Leafs)); err == nil {
+		t.Errorf("error: expected error for index out of range")
+	}
+	if _, _, err := tree.GetMerklePathAt(len(contents)); err == nil {
+		t.Errorf("error: expected error for index of the duplicate leaf")
+	}
+}
@@ -0,0 +1,130 @@
+package merklehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cbergoon/merkletree"
+)
+
+// Client fetches proofs from a Handler. Proofs are verified against roots the caller
+// trusts, never against the roots reported by the server.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the Handler at @baseURL using @httpClient, or
+// http.DefaultClient if @httpClient is nil.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: baseURL, httpClient: httpClient}
+}
+
+// get fetches @path with the query @query and decodes the JSON response into @v.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			return fmt.Errorf("error: %s", resp.Status)
+		}
+		return fmt.Errorf("error: %s: %s", resp.Status, e.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Roots returns the trees served.
+func (c *Client) Roots(ctx context.Context) ([]RootInfo, error) {
+	var resp RootsResponse
+	if err := c.get(ctx, "/roots", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Roots, nil
+}
+
+// Proof returns the proof of the leaf at @index of the tree with root @root.
+func (c *Client) Proof(ctx context.Context, root []byte, index int) (*Proof, error) {
+	var proof Proof
+	query := url.Values{"index": {strconv.Itoa(index)}}
+	if err := c.get(ctx, "/trees/"+hex.EncodeToString(root)+"/proof", query, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// ProofByHash returns the proof of the leaf with hash @leafHash of the tree with root @root.
+func (c *Client) ProofByHash(ctx context.Context, root []byte, leafHash []byte) (*Proof, error) {
+	var proof Proof
+	query := url.Values{"hash": {hex.EncodeToString(leafHash)}}
+	if err := c.get(ctx, "/trees/"+hex.EncodeToString(root)+"/proof", query, &proof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// Leaf returns the leaf at @index of the tree with root @root.
+func (c *Client) Leaf(ctx context.Context, root []byte, index int) (*Leaf, error) {
+	var leaf Leaf
+	if err := c.get(ctx, "/trees/"+hex.EncodeToString(root)+"/leaf/"+strconv.Itoa(index), nil, &leaf); err != nil {
+		return nil, err
+	}
+	return &leaf, nil
+}
+
+// VerifyContent fetches the proof of @content from the tree with root @root and returns
+// true if it proves @content against @root with the hash strategy @hashStrategy.
+func (c *Client) VerifyContent(ctx context.Context, root []byte, content merkletree.Content, hashStrategy string) (bool, error) {
+	leafHash, err := content.CalculateHash()
+	if err != nil {
+		return false, err
+	}
+	proof, err := c.ProofByHash(ctx, root, leafHash)
+	if err != nil {
+		return false, err
+	}
+	return proof.Verify(root, leafHash, hashStrategy)
+}
+
+// Verify returns true if the proof proves the leaf hash @leafHash against the trusted
+// root @root with the hash strategy @hashStrategy.
+func (p *Proof) Verify(root []byte, leafHash []byte, hashStrategy string) (bool, error) {
+	leaf, err := hex.DecodeString(p.Leaf)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(leaf, leafHash) {
+		return false, nil
+	}
+	merklePath := make([][]byte, len(p.Path))
+	for i, s := range p.Path {
+		if merklePath[i], err = hex.DecodeString(s); err != nil {
+			return false, err
+		}
+	}
+	if len(p.Sides) != len(merklePath) {
+		return false, errors.New("error: merkle path and sides differ in length")
+	}
+	return merkletree.VerifyMerklePath(leafHash, root, merklePath, p.Sides, hashStrategy)
+}
@@ -0,0 +1,71 @@
+package merklehttp
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cbergoon/merkletree"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	tree := testTree(t, 7, "sha256")
+	other := testTree(t, 3, "keccak256")
+	srv := httptest.NewServer(NewHandler(tree, other))
+	defer srv.Close()
+	c := NewClient(srv.URL, srv.Client())
+
+	roots, err := c.Roots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 2 {
+		t.Fatalf("error: expected 2 roots got %d", len(roots))
+	}
+
+	for i := 0; i < 7; i++ {
+		proof, err := c.Proof(ctx, tree.MerkleRoot, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := proof.Verify(tree.MerkleRoot, tree.Leafs[i].Hash, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid proof", i)
+		}
+		// A proof does not verify against another root or for another leaf.
+		if ok, _ := proof.Verify(other.MerkleRoot, tree.Leafs[i].Hash, "sha256"); ok {
+			t.Errorf("[case:%d] error: expected proof to fail against another root", i)
+		}
+		if ok, _ := proof.Verify(tree.MerkleRoot, tree.Leafs[(i+1)%7].Hash, "sha256"); ok {
+			t.Errorf("[case:%d] error: expected proof to fail for another leaf", i)
+		}
+
+		ok, err = c.VerifyContent(ctx, tree.MerkleRoot, tree.Leafs[i].C, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected content to be verified", i)
+		}
+
+		leaf, err := c.Leaf(ctx, tree.MerkleRoot, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaf.Index != i {
+			t.Errorf("[case:%d] error: expected leaf %d got %d", i, i, leaf.Index)
+		}
+	}
+
+	if _, err := c.VerifyContent(ctx, tree.MerkleRoot, merkletree.ByteContent{Content: []byte("missing")}, "sha256"); err == nil {
+		t.Errorf("error: expected error for content not in tree")
+	}
+	if _, err := c.Proof(ctx, bytes.Repeat([]byte{1}, 32), 0); err == nil {
+		t.Errorf("error: expected error for unknown root")
+	}
+}
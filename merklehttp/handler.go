@@ -0,0 +1,241 @@
+// Package merklehttp serves proofs of the content of Merkle trees over HTTP and fetches
+// and verifies them.
+//
+// The Handler serves the following endpoints, all of which respond with JSON:
+//
+//	GET /roots                            RootsResponse listing the trees
+//	GET /trees/{root}/proof?index={i}     Proof of the leaf at index i
+//	GET /trees/{root}/proof?hash={hash}   Proof of the first leaf with the leaf hash
+//	GET /trees/{root}/leaf/{i}            Leaf at index i with its content
+//
+// Roots and hashes are hex encoded. Leaf indexes do not include the duplicate of the last
+// leaf a tree with an odd number of leafs holds. Errors are answered with an
+// ErrorResponse and a 4xx status code.
+package merklehttp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cbergoon/merkletree"
+)
+
+// RootInfo describes a tree served by a Handler.
+type RootInfo struct {
+	Root         string `json:"root"`
+	HashStrategy string `json:"hash_strategy"`
+	Leafs        int    `json:"leafs"`
+}
+
+// RootsResponse is the response of GET /roots.
+type RootsResponse struct {
+	Roots []RootInfo `json:"roots"`
+}
+
+// Proof is the response of GET /trees/{root}/proof. @Path holds the sibling hashes from
+// the leaf up to the root and @Sides their indexes in the format of
+// merkletree.VerifyMerklePath: 1 if the sibling is the right node, 0 if it is the left node.
+type Proof struct {
+	Root         string   `json:"root"`
+	HashStrategy string   `json:"hash_strategy"`
+	Index        int      `json:"index"`
+	Leaf         string   `json:"leaf"`
+	Path         []string `json:"path"`
+	Sides        []int64  `json:"sides"`
+}
+
+// Leaf is the response of GET /trees/{root}/leaf/{i}. @Content is the JSON encoding of the
+// content of the leaf.
+type Leaf struct {
+	Root    string          `json:"root"`
+	Index   int             `json:"index"`
+	Hash    string          `json:"hash"`
+	Content json.RawMessage `json:"content"`
+}
+
+// ErrorResponse is the response of a failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves proofs of the trees added to it. It is safe for concurrent use. Trees
+// must not be modified after they are added.
+type Handler struct {
+	mu    sync.RWMutex
+	trees []*merkletree.MerkleTree
+}
+
+// NewHandler creates a Handler serving the trees @trees.
+func NewHandler(trees ...*merkletree.MerkleTree) *Handler {
+	return &Handler{trees: trees}
+}
+
+// Add adds the tree @tree to the served trees.
+func (h *Handler) Add(tree *merkletree.MerkleTree) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.trees = append(h.trees, tree)
+}
+
+// Remove stops serving the trees with root @root.
+func (h *Handler) Remove(root []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	trees := h.trees[:0:0]
+	for _, tree := range h.trees {
+		if !bytes.Equal(tree.MerkleRoot, root) {
+			trees = append(trees, tree)
+		}
+	}
+	h.trees = trees
+}
+
+// tree returns the tree with the hex encoded root @root, or nil if it is not served.
+func (h *Handler) tree(root string) *merkletree.MerkleTree {
+	b, err := hex.DecodeString(root)
+	if err != nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, tree := range h.trees {
+		if bytes.Equal(tree.MerkleRoot, b) {
+			return tree
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if r.URL.Path == "/roots" {
+		h.serveRoots(w)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/trees/"), "/")
+	if !strings.HasPrefix(r.URL.Path, "/trees/") || len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	tree := h.tree(parts[0])
+	if tree == nil {
+		writeError(w, http.StatusNotFound, "unknown root "+parts[0])
+		return
+	}
+	switch {
+	case len(parts) == 2 && parts[1] == "proof":
+		h.serveProof(w, r, tree)
+	case len(parts) == 3 && parts[1] == "leaf":
+		h.serveLeaf(w, tree, parts[2])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveRoots serves GET /roots.
+func (h *Handler) serveRoots(w http.ResponseWriter) {
+	h.mu.RLock()
+	resp := RootsResponse{Roots: make([]RootInfo, len(h.trees))}
+	for i, tree := range h.trees {
+		resp.Roots[i] = RootInfo{
+			Root:         hex.EncodeToString(tree.MerkleRoot),
+			HashStrategy: tree.HashStrategy,
+			Leafs:        tree.LeafCount(),
+		}
+	}
+	h.mu.RUnlock()
+	writeJSON(w, resp)
+}
+
+// serveProof serves GET /trees/{root}/proof.
+func (h *Handler) serveProof(w http.ResponseWriter, r *http.Request, tree *merkletree.MerkleTree) {
+	query := r.URL.Query()
+	index := -1
+	switch {
+	case query.Has("index"):
+		i, err := strconv.Atoi(query.Get("index"))
+		if err != nil || i < 0 || i >= tree.LeafCount() {
+			writeError(w, http.StatusBadRequest, "invalid index "+query.Get("index"))
+			return
+		}
+		index = i
+	case query.Has("hash"):
+		hash, err := hex.DecodeString(query.Get("hash"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid hash "+query.Get("hash"))
+			return
+		}
+		for i, leaf := range tree.Leafs[:tree.LeafCount()] {
+			if bytes.Equal(leaf.Hash, hash) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			writeError(w, http.StatusNotFound, "hash not in tree")
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "index or hash required")
+		return
+	}
+	merklePath, sides, err := tree.GetMerklePathAt(index)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	proof := Proof{
+		Root:         hex.EncodeToString(tree.MerkleRoot),
+		HashStrategy: tree.HashStrategy,
+		Index:        index,
+		Leaf:         hex.EncodeToString(tree.Leafs[index].Hash),
+		Path:         make([]string, len(merklePath)),
+		Sides:        sides,
+	}
+	for i, hash := range merklePath {
+		proof.Path[i] = hex.EncodeToString(hash)
+	}
+	writeJSON(w, proof)
+}
+
+// serveLeaf serves GET /trees/{root}/leaf/{i}.
+func (h *Handler) serveLeaf(w http.ResponseWriter, tree *merkletree.MerkleTree, index string) {
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= tree.LeafCount() {
+		writeError(w, http.StatusBadRequest, "invalid index "+index)
+		return
+	}
+	content, err := json.Marshal(tree.Leafs[i].C)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, Leaf{
+		Root:    hex.EncodeToString(tree.MerkleRoot),
+		Index:   i,
+		Hash:    hex.EncodeToString(tree.Leafs[i].Hash),
+		Content: content,
+	})
+}
+
+// writeJSON writes @v as JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error @msg with the status code @code.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: msg})
+}
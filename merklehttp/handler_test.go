@@ -0,0 +1,98 @@
+package merklehttp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cbergoon/merkletree"
+)
+
+// testTree returns a tree of @n ByteContents holding the hashes of "item-0", "item-1", ...
+func testTree(t *testing.T, n int, hashStrategy string) *merkletree.MerkleTree {
+	var cs []merkletree.Content
+	for i := 0; i < n; i++ {
+		h := merkletree.GetHashStrategies()[hashStrategy]
+		h.Write([]byte{'i', 't', 'e', 'm', '-', byte('0' + i)})
+		cs = append(cs, merkletree.ByteContent{Content: h.Sum(nil)})
+	}
+	tree, err := merkletree.NewTreeWithHashStrategy(cs, hashStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestHandler(t *testing.T) {
+	tree := testTree(t, 5, "sha256")
+	other := testTree(t, 2, "keccak256")
+	h := NewHandler(tree)
+	h.Add(other)
+	root := hex.EncodeToString(tree.MerkleRoot)
+
+	tables := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{"GET", "/roots", 200},
+		{"GET", "/trees/" + root + "/proof?index=0", 200},
+		{"GET", "/trees/" + root + "/proof?index=4", 200},
+		{"GET", "/trees/" + root + "/proof?hash=" + hex.EncodeToString(tree.Leafs[2].Hash), 200},
+		{"GET", "/trees/" + root + "/leaf/3", 200},
+		{"GET", "/trees/" + root + "/proof?index=5", 400},
+		{"GET", "/trees/" + root + "/proof?index=x", 400},
+		{"GET", "/trees/" + root + "/proof", 400},
+		{"GET", "/trees/" + root + "/proof?hash=zz", 400},
+		{"GET", "/trees/" + root + "/proof?hash=00", 404},
+		{"GET", "/trees/" + root + "/leaf/-1", 400},
+		{"GET", "/trees/" + root + "/leaf", 404},
+		{"GET", "/trees/00/proof?index=0", 404},
+		{"GET", "/trees", 404},
+		{"GET", "/", 404},
+		{"POST", "/roots", 405},
+	}
+	for i, table := range tables {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(table.method, table.path, nil))
+		if rec.Code != table.code {
+			t.Errorf("[case:%d] error: %s %s expected status %d got %d", i, table.method, table.path, table.code, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("[case:%d] error: expected JSON response got %s", i, ct)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/roots", nil))
+	var roots RootsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&roots); err != nil {
+		t.Fatal(err)
+	}
+	if len(roots.Roots) != 2 || roots.Roots[0] != (RootInfo{root, "sha256", 5}) || roots.Roots[1].HashStrategy != "keccak256" {
+		t.Errorf("error: unexpected roots %v", roots.Roots)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/trees/"+root+"/leaf/3", nil))
+	var leaf Leaf
+	if err := json.NewDecoder(rec.Body).Decode(&leaf); err != nil {
+		t.Fatal(err)
+	}
+	var content merkletree.ByteContent
+	if err := json.Unmarshal(leaf.Content, &content); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := content.Equals(tree.Leafs[3].C); !ok || leaf.Hash != hex.EncodeToString(tree.Leafs[3].Hash) {
+		t.Errorf("error: unexpected leaf %v", leaf)
+	}
+
+	h.Remove(tree.MerkleRoot)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/trees/"+root+"/leaf/3", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("error: expected removed tree not to be served")
+	}
+}
@@ -0,0 +1,142 @@
+// Package merklepb holds the protobuf schema merkletree.proto of proofs, signed tree heads
+// and StorageBucket leaves, for consumers in other languages, and converts between the
+// messages and the types of the merkletree package.
+package merklepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative merkletree.proto
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/cbergoon/merkletree"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewInclusionProof returns the proof of the leaf at @index of the tree @tree.
+func NewInclusionProof(tree *merkletree.MerkleTree, index int) (*InclusionProof, error) {
+	if index < 0 || index >= tree.LeafCount() {
+		return nil, errors.New("error: leaf index out of range")
+	}
+	merklePath, sides, err := tree.GetMerklePathAt(index)
+	if err != nil {
+		return nil, err
+	}
+	p := &InclusionProof{
+		Root:         tree.MerkleRoot,
+		HashStrategy: tree.HashStrategy,
+		LeafIndex:    uint64(index),
+		LeafHash:     tree.Leafs[index].Hash,
+		Path:         merklePath,
+		Sides:        make([]Side, len(sides)),
+	}
+	// GetMerklePathAt marks a right sibling with 1 and a left sibling with 0.
+	for i, side := range sides {
+		p.Sides[i] = Side_SIDE_LEFT
+		if side == 1 {
+			p.Sides[i] = Side_SIDE_RIGHT
+		}
+	}
+	return p, nil
+}
+
+// Verify returns true if the proof proves its leaf hash against the trusted root @root.
+func (x *InclusionProof) Verify(root []byte) (bool, error) {
+	index := make([]int64, len(x.GetSides()))
+	for i, side := range x.GetSides() {
+		switch side {
+		case Side_SIDE_LEFT:
+			index[i] = 0
+		case Side_SIDE_RIGHT:
+			index[i] = 1
+		default:
+			return false, errors.New("error: unknown side of merkle path")
+		}
+	}
+	return merkletree.VerifyMerklePath(x.GetLeafHash(), root, x.GetPath(), index, x.GetHashStrategy())
+}
+
+// NewTreeHead returns the head of the tree @tree of the topic @topic sealed at @ts.
+func NewTreeHead(tree *merkletree.MerkleTree, topic string, ts time.Time) *TreeHead {
+	return &TreeHead{
+		Root:         tree.MerkleRoot,
+		HashStrategy: tree.HashStrategy,
+		TreeSize:     uint64(tree.LeafCount()),
+		Timestamp:    fromTime(ts),
+		Topic:        topic,
+	}
+}
+
+// SignTreeHead encodes @head and signs it with the Ed25519 key @key named @keyName.
+func SignTreeHead(head *TreeHead, keyName string, key ed25519.PrivateKey) (*SignedTreeHead, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(head)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTreeHead{
+		Head: b,
+		Signatures: []*Signature{{
+			KeyName:   keyName,
+			Signature: ed25519.Sign(key, b),
+		}},
+	}, nil
+}
+
+// Verify returns the tree head if it carries a valid signature of the key @keyName with
+// the public key @pub, and an error otherwise.
+func (x *SignedTreeHead) Verify(keyName string, pub ed25519.PublicKey) (*TreeHead, error) {
+	for _, sig := range x.GetSignatures() {
+		if sig.GetKeyName() != keyName || !ed25519.Verify(pub, x.GetHead(), sig.GetSignature()) {
+			continue
+		}
+		var head TreeHead
+		if err := proto.Unmarshal(x.GetHead(), &head); err != nil {
+			return nil, err
+		}
+		return &head, nil
+	}
+	return nil, errors.New("error: no valid signature of key " + keyName)
+}
+
+// FromStorageBucket returns the message of the StorageBucket @sb.
+func FromStorageBucket(sb merkletree.StorageBucket) *StorageBucket {
+	return &StorageBucket{
+		Content:     sb.Content,
+		Topic:       sb.Topic,
+		Size:        sb.Size,
+		Id:          sb.ID,
+		Timestamp:   fromTime(sb.Timestamp),
+		HashVersion: uint32(sb.HashVersion),
+	}
+}
+
+// ToStorageBucket returns the StorageBucket of the message.
+func (x *StorageBucket) ToStorageBucket() (merkletree.StorageBucket, error) {
+	if x.GetHashVersion() > 255 {
+		return merkletree.StorageBucket{}, errors.New("error: unknown hash version")
+	}
+	sb := merkletree.StorageBucket{
+		Content:     x.GetContent(),
+		Topic:       x.GetTopic(),
+		Size:        x.GetSize(),
+		ID:          x.GetId(),
+		HashVersion: merkletree.HashVersion(x.GetHashVersion()),
+	}
+	if x.GetTimestamp() != nil {
+		if err := x.GetTimestamp().CheckValid(); err != nil {
+			return merkletree.StorageBucket{}, err
+		}
+		sb.Timestamp = x.GetTimestamp().AsTime()
+	}
+	return sb, nil
+}
+
+// fromTime returns the timestamp of @t, or nil for the zero time.
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,177 @@
+package merklepb
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cbergoon/merkletree"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// fixtureTree returns the tree of the fixtures, whose leafs are the SHA-256 hashes of
+// "item-0" to "item-4".
+func fixtureTree(t *testing.T) *merkletree.MerkleTree {
+	var cs []merkletree.Content
+	for _, item := range []string{"item-0", "item-1", "item-2", "item-3", "item-4"} {
+		h := sha256.Sum256([]byte(item))
+		cs = append(cs, merkletree.ByteContent{Content: h[:]})
+	}
+	tree, err := merkletree.NewTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// fixtureKey returns the Ed25519 key of the fixtures, derived from a seed of 32 bytes 0x01.
+func fixtureKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+}
+
+// fixtureTime is the timestamp of the fixtures.
+var fixtureTime = time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+
+// checkFixture checks that @m equals the text fixture @name.txtpb and encodes to the
+// binary fixture @name.binpb, and returns the message decoded from the binary fixture.
+func checkFixture(t *testing.T, name string, m proto.Message) proto.Message {
+	text, err := os.ReadFile(filepath.Join("testdata", name+".txtpb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err := os.ReadFile(filepath.Join("testdata", name+".binpb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromText := m.ProtoReflect().New().Interface()
+	if err := prototext.Unmarshal(text, fromText); err != nil {
+		t.Fatalf("[%s] %v", name, err)
+	}
+	if !proto.Equal(m, fromText) {
+		t.Errorf("[%s] error: message differs from text fixture", name)
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, bin) {
+		t.Errorf("[%s] error: encoding differs from binary fixture", name)
+	}
+	fromBin := m.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(bin, fromBin); err != nil {
+		t.Fatalf("[%s] %v", name, err)
+	}
+	if !proto.Equal(fromBin, fromText) {
+		t.Errorf("[%s] error: binary fixture differs from text fixture", name)
+	}
+	return fromBin
+}
+
+func TestInclusionProof(t *testing.T) {
+	tree := fixtureTree(t)
+	for i := 0; i < 5; i++ {
+		p, err := NewInclusionProof(tree, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := p.Verify(tree.MerkleRoot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid proof", i)
+		}
+	}
+	if _, err := NewInclusionProof(tree, 5); err == nil {
+		t.Errorf("error: expected error for the duplicate leaf")
+	}
+
+	p, _ := NewInclusionProof(tree, 2)
+	fixture := checkFixture(t, "inclusion_proof", p).(*InclusionProof)
+	if ok, _ := fixture.Verify(tree.MerkleRoot); !ok {
+		t.Errorf("error: expected fixture proof to be valid")
+	}
+	// The sides of the proof of leaf 2 are right, left, right: field 6, packed, 3 bytes.
+	bin, err := os.ReadFile(filepath.Join("testdata", "inclusion_proof.binpb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(bin, []byte{0x32, 0x03, 0x02, 0x01, 0x02}) {
+		t.Errorf("error: unexpected encoding of sides in binary fixture")
+	}
+	fixture.Sides[0] = Side_SIDE_LEFT
+	if ok, _ := fixture.Verify(tree.MerkleRoot); ok {
+		t.Errorf("error: expected tampered proof to be invalid")
+	}
+	fixture.Sides[0] = Side_SIDE_UNSPECIFIED
+	if _, err := fixture.Verify(tree.MerkleRoot); err == nil {
+		t.Errorf("error: expected error for unspecified side")
+	}
+}
+
+func TestSignedTreeHead(t *testing.T) {
+	tree := fixtureTree(t)
+	key := fixtureKey()
+	head := NewTreeHead(tree, "trades", fixtureTime)
+	sth, err := SignTreeHead(head, "collector", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := checkFixture(t, "signed_tree_head", sth).(*SignedTreeHead)
+	verified, err := fixture.Verify("collector", key.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(verified, head) || verified.GetTreeSize() != 5 || !verified.GetTimestamp().AsTime().Equal(fixtureTime) {
+		t.Errorf("error: unexpected verified tree head %v", verified)
+	}
+	if _, err := fixture.Verify("other", key.Public().(ed25519.PublicKey)); err == nil {
+		t.Errorf("error: expected error for unknown key name")
+	}
+	fixture.Head[len(fixture.Head)-1] ^= 1
+	if _, err := fixture.Verify("collector", key.Public().(ed25519.PublicKey)); err == nil {
+		t.Errorf("error: expected error for tampered head")
+	}
+}
+
+func TestStorageBucket(t *testing.T) {
+	pool := merkletree.NewBucketPool(1, 64, "trades")
+	w := merkletree.NewBucketWriter(pool)
+	w.Write([]byte("btc-usd 42000.5"))
+	w.Flush()
+	tree, err := merkletree.MakeTree(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := tree.Leafs[0].C.(merkletree.StorageBucket)
+	leaf.ID = "b1"
+	leaf.Timestamp = fixtureTime
+
+	m := FromStorageBucket(leaf)
+	fixture := checkFixture(t, "storage_bucket", m).(*StorageBucket)
+	sb, err := fixture.ToStorageBucket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := sb.Equals(leaf); !ok {
+		t.Errorf("error: expected %v got %v", leaf, sb)
+	}
+	h1, _ := sb.CalculateHash()
+	h2, _ := leaf.CalculateHash()
+	if !bytes.Equal(h1, h2) {
+		t.Errorf("error: expected decoded bucket to have the same hash")
+	}
+
+	sb, err = FromStorageBucket(merkletree.StorageBucket{Content: []byte("a")}).ToStorageBucket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sb.Timestamp.IsZero() {
+		t.Errorf("error: expected zero timestamp to round trip")
+	}
+}
@@ -0,0 +1,388 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// -----------------------------------------------------------------------
+// OpenZeppelin compatibility
+// -----------------------------------------------------------------------
+
+// standardFormat is the format tag of the JSON dump of a StandardMerkleTree.
+const standardFormat = "standard-v1"
+
+// keccak256 returns the Keccak-256 hash of the concatenation of @data as used by Ethereum.
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// SortedPairHash returns the hash of the pair @a, @b as computed by OpenZeppelin's
+// MerkleProof: keccak256 of both hashes concatenated in ascending byte order.
+func SortedPairHash(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return keccak256(a, b)
+}
+
+// StandardLeafHash returns the leaf hash of @value as computed by OpenZeppelin's
+// StandardMerkleTree: keccak256(keccak256(abi.encode(@value))) with the Solidity types
+// @leafEncoding.
+func StandardLeafHash(leafEncoding []string, value []interface{}) ([]byte, error) {
+	encoded, err := abiEncode(leafEncoding, value)
+	if err != nil {
+		return nil, err
+	}
+	return keccak256(keccak256(encoded)), nil
+}
+
+// standardValue is a value of a StandardMerkleTree along with the index of its leaf.
+type standardValue struct {
+	Value     []interface{} `json:"value"`
+	TreeIndex int           `json:"treeIndex"`
+}
+
+// StandardMerkleTree is a Merkle tree compatible with OpenZeppelin's merkle-tree library
+// and the MerkleProof contract. Leafs are double hashed ABI encoded values sorted by hash,
+// sibling pairs are sorted before hashing, so that proofs need no direction bits. The tree
+// is stored as an array with the root at index 0 and the children of node i at 2i+1 and 2i+2.
+type StandardMerkleTree struct {
+	LeafEncoding []string
+	tree         [][]byte
+	values       []standardValue
+}
+
+// NewStandardMerkleTree creates a new StandardMerkleTree from @values, each of which is
+// ABI encoded with the Solidity types @leafEncoding. Values are strings for addresses,
+// integers (decimal or 0x-prefixed hex), bytes and strings, and bools for bools.
+func NewStandardMerkleTree(values [][]interface{}, leafEncoding []string) (*StandardMerkleTree, error) {
+	if len(values) == 0 {
+		return nil, errors.New("error: cannot construct tree with no content")
+	}
+	type hashedValue struct {
+		valueIndex int
+		hash       []byte
+	}
+	hashed := make([]hashedValue, len(values))
+	for i, value := range values {
+		hash, err := StandardLeafHash(leafEncoding, value)
+		if err != nil {
+			return nil, err
+		}
+		hashed[i] = hashedValue{valueIndex: i, hash: hash}
+	}
+	sort.SliceStable(hashed, func(i, j int) bool {
+		return bytes.Compare(hashed[i].hash, hashed[j].hash) < 0
+	})
+
+	t := &StandardMerkleTree{
+		LeafEncoding: leafEncoding,
+		tree:         make([][]byte, 2*len(values)-1),
+		values:       make([]standardValue, len(values)),
+	}
+	for leafIndex, hv := range hashed {
+		treeIndex := len(t.tree) - 1 - leafIndex
+		t.tree[treeIndex] = hv.hash
+		t.values[hv.valueIndex] = standardValue{Value: values[hv.valueIndex], TreeIndex: treeIndex}
+	}
+	for i := len(t.tree) - 1 - len(values); i >= 0; i-- {
+		t.tree[i] = SortedPairHash(t.tree[2*i+1], t.tree[2*i+2])
+	}
+	return t, nil
+}
+
+// Root returns the root hash of the tree.
+func (t *StandardMerkleTree) Root() []byte {
+	return t.tree[0]
+}
+
+// Len returns the number of values in the tree.
+func (t *StandardMerkleTree) Len() int {
+	return len(t.values)
+}
+
+// Value returns the value with index @i.
+func (t *StandardMerkleTree) Value(i int) []interface{} {
+	return t.values[i].Value
+}
+
+// GetProof returns the proof of the value with index @i, i.e. the sibling hashes from
+// its leaf up to the root.
+func (t *StandardMerkleTree) GetProof(i int) ([][]byte, error) {
+	if i < 0 || i >= len(t.values) {
+		return nil, errors.New("error: value index out of range")
+	}
+	var proof [][]byte
+	for j := t.values[i].TreeIndex; j > 0; j = (j - 1) / 2 {
+		sibling := j + 1
+		if j%2 == 0 {
+			sibling = j - 1
+		}
+		proof = append(proof, t.tree[sibling])
+	}
+	return proof, nil
+}
+
+// GetProofHex returns the proof of the value with index @i as 0x-prefixed hex strings,
+// as expected by the MerkleProof contract.
+func (t *StandardMerkleTree) GetProofHex(i int) ([]string, error) {
+	proof, err := t.GetProof(i)
+	if err != nil {
+		return nil, err
+	}
+	return hexArray(proof), nil
+}
+
+// NewSortedPairTree creates a new MerkleTree using the content @cs whose nodes are hashed
+// like OpenZeppelin's MerkleProof: keccak256 of both child hashes in ascending order. The
+// Merkle paths of the tree need no indexes and can be checked with VerifySortedPairProof
+// or on chain. Note that unlike StandardMerkleTree the leafs are neither hashed nor sorted.
+func NewSortedPairTree(cs []Content) (*MerkleTree, error) {
+	t := &MerkleTree{
+		HashStrategy: "keccak256",
+		SortPairs:    true,
+	}
+	if err := t.RebuildTreeWith(cs); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// VerifySortedPairProof returns true if hashing @leafHash along @proof with SortedPairHash
+// yields @root.
+func VerifySortedPairProof(root []byte, leafHash []byte, proof [][]byte) bool {
+	hash := leafHash
+	for _, sibling := range proof {
+		hash = SortedPairHash(hash, sibling)
+	}
+	return bytes.Equal(hash, root)
+}
+
+// VerifyStandardProof returns true if @proof proves @value, encoded with @leafEncoding,
+// against @root.
+func VerifyStandardProof(root []byte, leafEncoding []string, value []interface{}, proof [][]byte) (bool, error) {
+	leafHash, err := StandardLeafHash(leafEncoding, value)
+	if err != nil {
+		return false, err
+	}
+	return VerifySortedPairProof(root, leafHash, proof), nil
+}
+
+// Validate recomputes all leaf and node hashes and returns an error if any of them differ.
+func (t *StandardMerkleTree) Validate() error {
+	if len(t.tree) == 0 || len(t.tree) != 2*len(t.values)-1 {
+		return errors.New("error: tree size does not match number of values")
+	}
+	for i, v := range t.values {
+		if v.TreeIndex < len(t.tree)-len(t.values) || v.TreeIndex >= len(t.tree) {
+			return fmt.Errorf("error: value %d is not a leaf", i)
+		}
+		hash, err := StandardLeafHash(t.LeafEncoding, v.Value)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash, t.tree[v.TreeIndex]) {
+			return fmt.Errorf("error: leaf hash of value %d is invalid", i)
+		}
+	}
+	for i := len(t.tree) - 1 - len(t.values); i >= 0; i-- {
+		if !bytes.Equal(t.tree[i], SortedPairHash(t.tree[2*i+1], t.tree[2*i+2])) {
+			return fmt.Errorf("error: hash of node %d is invalid", i)
+		}
+	}
+	return nil
+}
+
+// standardDump is the JSON dump format of OpenZeppelin's StandardMerkleTree.
+type standardDump struct {
+	Format       string          `json:"format"`
+	Tree         []string        `json:"tree"`
+	Values       []standardValue `json:"values"`
+	LeafEncoding []string        `json:"leafEncoding"`
+}
+
+// MarshalJSON dumps the tree in the format of StandardMerkleTree.dump().
+func (t *StandardMerkleTree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(standardDump{
+		Format:       standardFormat,
+		Tree:         hexArray(t.tree),
+		Values:       t.values,
+		LeafEncoding: t.LeafEncoding,
+	})
+}
+
+// UnmarshalJSON loads a tree dumped by StandardMerkleTree.dump() and validates it.
+func (t *StandardMerkleTree) UnmarshalJSON(data []byte) error {
+	var dump standardDump
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&dump); err != nil {
+		return err
+	}
+	if dump.Format != standardFormat {
+		return fmt.Errorf("error: unknown format %s", dump.Format)
+	}
+	tree := make([][]byte, len(dump.Tree))
+	for i, s := range dump.Tree {
+		b, err := decodeHex(s)
+		if err != nil {
+			return err
+		}
+		tree[i] = b
+	}
+	loaded := StandardMerkleTree{
+		LeafEncoding: dump.LeafEncoding,
+		tree:         tree,
+		values:       dump.Values,
+	}
+	if err := loaded.Validate(); err != nil {
+		return err
+	}
+	*t = loaded
+	return nil
+}
+
+// hexArray encodes @hashes as 0x-prefixed hex strings.
+func hexArray(hashes [][]byte) []string {
+	s := make([]string, len(hashes))
+	for i, h := range hashes {
+		s[i] = "0x" + hex.EncodeToString(h)
+	}
+	return s
+}
+
+// decodeHex decodes a hex string with optional 0x prefix.
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+}
+
+// abiEncode returns the Solidity abi.encode of @value with the types @types. Supported are
+// the static types address, bool, uintN, intN and bytesN and the dynamic types bytes and string.
+func abiEncode(types []string, value []interface{}) ([]byte, error) {
+	if len(types) != len(value) {
+		return nil, errors.New("error: value does not match leaf encoding")
+	}
+	var head, tail []byte
+	headSize := 32 * len(types)
+	for i, typ := range types {
+		switch typ {
+		case "bytes", "string":
+			var data []byte
+			s, ok := value[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("error: expected string for %s", typ)
+			}
+			if typ == "string" {
+				data = []byte(s)
+			} else {
+				var err error
+				if data, err = decodeHex(s); err != nil {
+					return nil, err
+				}
+			}
+			head = append(head, abiWord(big.NewInt(int64(headSize+len(tail))))...)
+			tail = append(tail, abiWord(big.NewInt(int64(len(data))))...)
+			tail = append(tail, data...)
+			if pad := len(data) % 32; pad != 0 {
+				tail = append(tail, make([]byte, 32-pad)...)
+			}
+		default:
+			word, err := abiEncodeStatic(typ, value[i])
+			if err != nil {
+				return nil, err
+			}
+			head = append(head, word...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+// abiEncodeStatic returns the 32 byte ABI encoding of the static value @v of type @typ.
+func abiEncodeStatic(typ string, v interface{}) ([]byte, error) {
+	switch {
+	case typ == "bool":
+		b, ok := v.(bool)
+		if !ok {
+			s := fmt.Sprint(v)
+			var err error
+			if b, err = strconv.ParseBool(s); err != nil {
+				return nil, fmt.Errorf("error: invalid bool %v", v)
+			}
+		}
+		if b {
+			return abiWord(big.NewInt(1)), nil
+		}
+		return abiWord(big.NewInt(0)), nil
+	case typ == "address":
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("error: expected string for address")
+		}
+		b, err := decodeHex(s)
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("error: invalid address %s", s)
+		}
+		return append(make([]byte, 12), b...), nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("error: unsupported type %s", typ)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("error: expected string for %s", typ)
+		}
+		b, err := decodeHex(s)
+		if err != nil || len(b) != size {
+			return nil, fmt.Errorf("error: invalid %s %s", typ, s)
+		}
+		return append(b, make([]byte, 32-size)...), nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits := 256
+		if digits := strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"); digits != "" {
+			var err error
+			if bits, err = strconv.Atoi(digits); err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+				return nil, fmt.Errorf("error: unsupported type %s", typ)
+			}
+		}
+		n, ok := new(big.Int).SetString(strings.TrimSpace(fmt.Sprint(v)), 0)
+		if !ok {
+			return nil, fmt.Errorf("error: invalid %s %v", typ, v)
+		}
+		min, max := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		if signed {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("error: %v out of range for %s", v, typ)
+		}
+		if n.Sign() < 0 {
+			// two's complement
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return abiWord(n), nil
+	}
+	return nil, fmt.Errorf("error: unsupported type %s", typ)
+}
+
+// abiWord returns the non-negative @n as a big-endian 32 byte word.
+func abiWord(n *big.Int) []byte {
+	word := make([]byte, 32)
+	return n.FillBytes(word)
+}
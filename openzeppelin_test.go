@@ -0,0 +1,168 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Example of the OpenZeppelin merkle-tree library.
+var standardValues = [][]interface{}{
+	{"0x1111111111111111111111111111111111111111", "5000000000000000000"},
+	{"0x2222222222222222222222222222222222222222", "2500000000000000000"},
+}
+
+const standardRoot = "d4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77"
+
+func TestStandardMerkleTree_Root(t *testing.T) {
+	tree, err := NewStandardMerkleTree(standardValues, []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(tree.Root()) != standardRoot {
+		t.Errorf("error: expected root %s got %x", standardRoot, tree.Root())
+	}
+}
+
+func TestSortedPairTree(t *testing.T) {
+	// The leafs of the standard tree yield its root in a sorted-pair MerkleTree.
+	var cs []Content
+	for _, value := range standardValues {
+		leafHash, err := StandardLeafHash([]string{"address", "uint256"}, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs = append(cs, ByteContent{Content: leafHash})
+	}
+	tree, err := NewSortedPairTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(tree.MerkleRoot) != standardRoot {
+		t.Errorf("error: expected root %s got %x", standardRoot, tree.MerkleRoot)
+	}
+
+	cs = nil
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		cs = append(cs, ByteContent{Content: keccak256([]byte(v))})
+	}
+	tree, err = NewSortedPairTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := tree.VerifyTree(); err != nil || !ok {
+		t.Errorf("error: expected valid tree, got %v, %v", ok, err)
+	}
+	for i, c := range cs {
+		path, _, err := tree.GetMerklePathAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leafHash, _ := c.CalculateHash()
+		if !VerifySortedPairProof(tree.MerkleRoot, leafHash, path) {
+			t.Errorf("[leaf:%d] error: expected valid sorted pair proof", i)
+		}
+		if ok, err := tree.VerifyContent(c); err != nil || !ok {
+			t.Errorf("[leaf:%d] error: expected content to verify, got %v, %v", i, ok, err)
+		}
+	}
+}
+
+func TestStandardMerkleTree_Proof(t *testing.T) {
+	leafEncoding := []string{"address", "uint256", "string", "bool", "bytes32", "int8", "bytes"}
+	var values [][]interface{}
+	for _, v := range []string{"1", "2", "3", "4", "5"} {
+		values = append(values, []interface{}{
+			"0x" + string(bytes.Repeat([]byte(v), 40)),
+			"0x" + v + "0",
+			"name " + v,
+			v == "2",
+			"0x" + string(bytes.Repeat([]byte(v), 64)),
+			"-" + v,
+			"0x" + v + v,
+		})
+	}
+	tree, err := NewStandardMerkleTree(values, leafEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, value := range values {
+		proof, err := tree.GetProof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyStandardProof(tree.Root(), leafEncoding, value, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[value:%d] error: expected valid proof", i)
+		}
+		ok, err = VerifyStandardProof(tree.Root(), leafEncoding, values[(i+1)%len(values)], proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("[value:%d] error: expected invalid proof for other value", i)
+		}
+	}
+
+	invalid := [][]interface{}{
+		{"0x11", "1", "", true, "0x00", "0", "0x"},
+		{values[0][0], "-1", "", true, values[0][4], "0", "0x"},
+		{values[0][0], "1", "", true, values[0][4], "128", "0x"},
+		{values[0][0], "1", "", true, "0x00", "0", "0x"},
+		{values[0][0], "1"},
+	}
+	for i, value := range invalid {
+		if _, err := StandardLeafHash(leafEncoding, value); err == nil {
+			t.Errorf("[case:%d] error: expected error for invalid value", i)
+		}
+	}
+}
+
+func TestStandardMerkleTree_Dump(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "standard_tree.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded StandardMerkleTree
+	if err := json.Unmarshal(golden, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(loaded.Root()) != standardRoot {
+		t.Errorf("error: expected root %s got %x", standardRoot, loaded.Root())
+	}
+	tree, err := NewStandardMerkleTree(standardValues, []string{"address", "uint256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, got interface{}
+	json.Unmarshal(golden, &expected)
+	json.Unmarshal(dump, &got)
+	expectedJSON, _ := json.Marshal(expected)
+	gotJSON, _ := json.Marshal(got)
+	if !bytes.Equal(expectedJSON, gotJSON) {
+		t.Errorf("error: expected dump %s got %s", expectedJSON, gotJSON)
+	}
+
+	proof, err := loaded.GetProofHex(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof) != 1 || proof[0] != "0x"+hex.EncodeToString(loaded.tree[loaded.values[1].TreeIndex]) {
+		t.Errorf("error: unexpected proof %v", proof)
+	}
+
+	tampered := bytes.Replace(golden, []byte("5000000000000000000"), []byte("6000000000000000000"), 1)
+	if err := json.Unmarshal(tampered, &loaded); err == nil {
+		t.Errorf("error: expected error for tampered dump")
+	}
+}
@@ -0,0 +1,157 @@
+package merkletree
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TopicConfig configures the pool of a topic in a PoolManager.
+// @MaxNum is the number of buckets of the pool
+// @Size is the size of the buckets in bytes
+// @Encoding is the encoding of the buckets
+type TopicConfig struct {
+	Topic    string
+	MaxNum   uint64
+	Size     uint64
+	Encoding BucketEncoding
+}
+
+// SealCycle holds the trees of the topics sealed in one seal cycle of a PoolManager and
+// the forest over them. The trees of the forest are ordered like @Topics, which is sorted.
+type SealCycle struct {
+	Topics []string
+	Forest *Forest
+	Sealed time.Time
+}
+
+// MerkleRoot returns the cross-topic root of the seal cycle.
+func (sc *SealCycle) MerkleRoot() []byte {
+	return sc.Forest.MerkleRoot()
+}
+
+// Tree returns the tree of the topic @topic, or nil if the topic was not sealed in the cycle.
+func (sc *SealCycle) Tree(topic string) *MerkleTree {
+	i := sort.SearchStrings(sc.Topics, topic)
+	if i < len(sc.Topics) && sc.Topics[i] == topic {
+		return sc.Forest.Trees[i]
+	}
+	return nil
+}
+
+// PoolManager owns the pools of many topics and routes writes to them by topic. The pools
+// of all topics are sealed together in seal cycles. It is safe for concurrent use.
+// Pools are only sealed by Seal: a full pool rejects writes with ErrPoolExhausted until
+// the next seal cycle, and writes are not recorded in a write-ahead log. Use a
+// PoolRotator per topic for pools that seal themselves when full or that recover from
+// a crash.
+type PoolManager struct {
+	mu      sync.RWMutex
+	configs map[string]TopicConfig
+	writers map[string]*BucketWriter
+	// pending holds the trees of topics sealed in a seal cycle that failed, which are
+	// part of the next seal cycle.
+	pending map[string]*MerkleTree
+}
+
+// NewPoolManager creates a PoolManager with pools for the topics @configs.
+func NewPoolManager(configs ...TopicConfig) (*PoolManager, error) {
+	m := &PoolManager{
+		configs: make(map[string]TopicConfig),
+		writers: make(map[string]*BucketWriter),
+		pending: make(map[string]*MerkleTree),
+	}
+	for _, cfg := range configs {
+		if err := m.AddTopic(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// AddTopic adds a pool for the topic @cfg.
+func (m *PoolManager) AddTopic(cfg TopicConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.configs[cfg.Topic]; ok {
+		return errors.New("error: duplicate topic " + cfg.Topic)
+	}
+	bp, err := NewBucketPoolWithEncoding(cfg.MaxNum, cfg.Size, cfg.Topic, cfg.Encoding)
+	if err != nil {
+		return err
+	}
+	m.configs[cfg.Topic] = cfg
+	m.writers[cfg.Topic] = NewBucketWriter(bp)
+	return nil
+}
+
+// Topics returns the sorted topics of the manager.
+func (m *PoolManager) Topics() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	topics := make([]string, 0, len(m.configs))
+	for topic := range m.configs {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Write appends the item @bs to the pool of the topic @topic, see BucketWriter.Write.
+// If ErrPoolExhausted is returned, the pool of the topic is full until the next seal cycle.
+func (m *PoolManager) Write(topic string, bs []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	w, ok := m.writers[topic]
+	if !ok {
+		return errors.New("error: unknown topic " + topic)
+	}
+	return w.Write(bs)
+}
+
+// Seal runs a seal cycle: the pool of every topic written to is sealed into a tree and a
+// fresh pool is started, and the forest over the trees is built. Returns nil if nothing
+// was written to any topic. If sealing a topic fails, the trees of the topics sealed
+// before are kept and are part of the next seal cycle instead of a new tree of their
+// topic, whose fresh pool is sealed in the cycle after.
+func (m *PoolManager) Seal() (*SealCycle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var topics []string
+	for topic, w := range m.writers {
+		if _, ok := m.pending[topic]; ok || w.Written() {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil, nil
+	}
+	sort.Strings(topics)
+	trees := make([]*MerkleTree, len(topics))
+	for i, topic := range topics {
+		if tree, ok := m.pending[topic]; ok {
+			trees[i] = tree
+			continue
+		}
+		cfg := m.configs[topic]
+		// The encoding was validated by AddTopic.
+		next, _ := NewBucketPoolWithEncoding(cfg.MaxNum, cfg.Size, cfg.Topic, cfg.Encoding)
+		tree, err := m.writers[topic].Seal(next)
+		if err != nil {
+			return nil, err
+		}
+		m.pending[topic] = tree
+		trees[i] = tree
+	}
+	forest, err := NewForest(trees)
+	if err != nil {
+		return nil, err
+	}
+	m.pending = make(map[string]*MerkleTree)
+	return &SealCycle{
+		Topics: topics,
+		Forest: forest,
+		Sealed: time.Now(),
+	}, nil
+}
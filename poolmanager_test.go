@@ -0,0 +1,148 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestPoolManager_Seal(t *testing.T) {
+	m, err := NewPoolManager(
+		TopicConfig{Topic: "trades", MaxNum: 4, Size: 64},
+		TopicConfig{Topic: "rates", MaxNum: 2, Size: 32, Encoding: BucketEncoding{Frames: FrameUvarint}},
+		TopicConfig{Topic: "quotes", MaxNum: 2, Size: 32},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPoolManager(TopicConfig{Topic: "a"}, TopicConfig{Topic: "a"}); err == nil {
+		t.Errorf("error: expected error for duplicate topic")
+	}
+	if err := m.Write("volumes", []byte("1")); err == nil {
+		t.Errorf("error: expected error for unknown topic")
+	}
+	if cycle, err := m.Seal(); err != nil || cycle != nil {
+		t.Errorf("error: expected no seal cycle without writes got %v, %v", cycle, err)
+	}
+
+	var wg sync.WaitGroup
+	for _, topic := range []string{"trades", "rates"} {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				if err := m.Write(topic, []byte(fmt.Sprintf("%s-%d", topic, i))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(topic)
+	}
+	wg.Wait()
+	cycle, err := m.Seal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(cycle.Topics) != "[rates trades]" {
+		t.Errorf("error: expected sealed topics [rates trades] got %v", cycle.Topics)
+	}
+	if cycle.Tree("quotes") != nil {
+		t.Errorf("error: expected no tree for topic without writes")
+	}
+	for _, topic := range cycle.Topics {
+		tree := cycle.Tree(topic)
+		for i := 0; i < 5; i++ {
+			item := []byte(fmt.Sprintf("%s-%d", topic, i))
+			ok, sb, err := DataInStorageTree(item, *tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok || sb.Topic != topic {
+				t.Errorf("[topic:%s] error: expected %s in tree of topic", topic, item)
+				continue
+			}
+			path, index, err := cycle.Forest.GetForestPath(sb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err = VerifyForestContent(sb, cycle.MerkleRoot(), path, index, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[topic:%s] error: expected bucket of %s to be proven against cycle root", topic, item)
+			}
+		}
+	}
+
+	// The next cycle starts with fresh pools.
+	if err := m.Write("quotes", []byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	next, err := m.Seal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(next.Topics) != "[quotes]" || bytes.Equal(next.MerkleRoot(), cycle.MerkleRoot()) {
+		t.Errorf("error: unexpected second seal cycle %v", next.Topics)
+	}
+}
+
+func TestPoolManager_Exhausted(t *testing.T) {
+	m, err := NewPoolManager(TopicConfig{Topic: "rates", MaxNum: 1, Size: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Write("rates", []byte("0.05")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Write("rates", []byte("0.06")); err != ErrPoolExhausted {
+		t.Errorf("error: expected ErrPoolExhausted got %v", err)
+	}
+	if _, err := m.Seal(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Write("rates", []byte("0.06")); err != nil {
+		t.Errorf("error: expected write to fresh pool to succeed got %v", err)
+	}
+}
+
+func TestPoolManager_SealFailure(t *testing.T) {
+	m, err := NewPoolManager(
+		TopicConfig{Topic: "quotes", MaxNum: 2, Size: 32},
+		TopicConfig{Topic: "rates", MaxNum: 2, Size: 32},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, topic := range []string{"quotes", "rates"} {
+		if err := m.Write(topic, []byte(topic)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Sealing rates fails as its seal cannot be logged, after quotes was sealed.
+	wal, err := OpenPoolWAL(filepath.Join(t.TempDir(), "rates.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal.Close()
+	m.writers["rates"].wal = wal
+	if _, err := m.Seal(); err == nil {
+		t.Fatal("error: expected error for failed seal")
+	}
+	m.writers["rates"].wal = nil
+	cycle, err := m.Seal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, topic := range []string{"quotes", "rates"} {
+		tree := cycle.Tree(topic)
+		if tree == nil {
+			t.Fatalf("[topic:%s] error: expected tree in seal cycle", topic)
+		}
+		if ok, _, err := DataInStorageTree([]byte(topic), *tree); err != nil || !ok {
+			t.Errorf("[topic:%s] error: expected item in tree of seal cycle, got %v, %v", topic, ok, err)
+		}
+	}
+}
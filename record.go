@@ -0,0 +1,262 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// -----------------------------------------------------------------------
+// Selective disclosure of records
+// -----------------------------------------------------------------------
+
+// recordHashStrategy is the hash strategy of the inner tree of a RecordContent.
+const recordHashStrategy = "sha256"
+
+// RecordField is a field of a RecordContent.
+// @Name is the JSON name of the field
+// @Value is the canonical JSON encoding of the value of the field
+// @Salt is the salt mixed into the leaf hash of the field
+type RecordField struct {
+	Name  string
+	Value json.RawMessage
+	Salt  []byte
+}
+
+// fieldContent implements Content for the name and value of a RecordField.
+type fieldContent struct {
+	name  string
+	value []byte
+}
+
+// CalculateHash calculates the SHA-256 hash of the length of the name (8 bytes, big
+// endian), the name, the length of the value and the value.
+func (fc fieldContent) CalculateHash() ([]byte, error) {
+	h := sha256.New()
+	var buf [8]byte
+	for _, b := range [][]byte{[]byte(fc.name), fc.value} {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(b)))
+		if _, err := h.Write(buf[:]); err != nil {
+			return nil, err
+		}
+		if _, err := h.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// Equals returns true if two fieldContents are identical, false otherwise
+func (fc fieldContent) Equals(other Content) (bool, error) {
+	o, ok := other.(fieldContent)
+	return ok && fc.name == o.name && bytes.Equal(fc.value, o.value), nil
+}
+
+// leaf returns the salted leaf of the field in the inner tree of its record.
+func (f RecordField) leaf() SaltedContent {
+	return SaltedContent{
+		Content: fieldContent{name: f.Name, value: f.Value},
+		Salt:    f.Salt,
+	}
+}
+
+// RecordContent implements Content for a structured record, such as a trade. Its hash is
+// the root of an inner Merkle tree over its salted fields in the order of Fields, which
+// NewRecordContent sorts by name, such that single fields can be disclosed without
+// revealing the others, see Disclose.
+type RecordContent struct {
+	Fields []RecordField
+}
+
+// NewRecordContent creates a RecordContent from the struct or map @v, whose fields are
+// those of its JSON encoding, salted by @salt.
+func NewRecordContent(v interface{}, salt SaltFunc) (RecordContent, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return RecordContent{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return RecordContent{}, errors.New("error: record is not a struct or map")
+	}
+	if len(fields) == 0 {
+		return RecordContent{}, errors.New("error: cannot construct record with no fields")
+	}
+	var rc RecordContent
+	for name, raw := range fields {
+		value, err := canonicalJSON(raw)
+		if err != nil {
+			return RecordContent{}, err
+		}
+		rc.Fields = append(rc.Fields, RecordField{Name: name, Value: value})
+	}
+	sort.Slice(rc.Fields, func(i, j int) bool {
+		return rc.Fields[i].Name < rc.Fields[j].Name
+	})
+	for i := range rc.Fields {
+		if rc.Fields[i].Salt, err = salt(i, rc.Fields[i].leaf().Content); err != nil {
+			return RecordContent{}, err
+		}
+	}
+	return rc, nil
+}
+
+// canonicalJSON returns the canonical encoding of the JSON value @raw, without
+// insignificant white space and with sorted object keys. Numbers are kept as written.
+func canonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Custom marshaler for RecordContent type
+func (rc RecordContent) MarshalJSON() ([]byte, error) {
+	type _RecordContent RecordContent
+	var out = struct {
+		Type string `json:"_type"`
+		_RecordContent
+	}{
+		Type:           "RecordContent",
+		_RecordContent: _RecordContent(rc),
+	}
+	return json.Marshal(out)
+}
+
+// Tree builds the inner tree of the record.
+func (rc RecordContent) Tree() (*MerkleTree, error) {
+	cs := make([]Content, len(rc.Fields))
+	for i, f := range rc.Fields {
+		cs[i] = f.leaf()
+	}
+	return NewTreeWithHashStrategy(cs, recordHashStrategy)
+}
+
+// CalculateHash calculates the hash of a RecordContent, which is the root of its inner tree.
+func (rc RecordContent) CalculateHash() ([]byte, error) {
+	tree, err := rc.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return tree.MerkleRoot, nil
+}
+
+// Equals returns true if two RecordContents have identical fields, false otherwise
+func (rc RecordContent) Equals(other Content) (bool, error) {
+	var o RecordContent
+	switch oc := other.(type) {
+	case RecordContent:
+		o = oc
+	case *RecordContent:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	if len(rc.Fields) != len(o.Fields) {
+		return false, nil
+	}
+	for i, f := range rc.Fields {
+		g := o.Fields[i]
+		if f.Name != g.Name || !bytes.Equal(f.Value, g.Value) || !bytes.Equal(f.Salt, g.Salt) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DisclosedField is a field of a Disclosure with its proof against the record root.
+type DisclosedField struct {
+	RecordField
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// Disclosure discloses chosen fields of a record. It proves the fields against the root
+// of the record, and the record against the root of the outer tree holding it.
+type Disclosure struct {
+	Fields     []DisclosedField
+	RecordRoot []byte
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// field returns the position of the field @name among the leafs of the inner tree, or -1.
+// The fields of a record that was not created by NewRecordContent need not be sorted.
+func (rc RecordContent) field(name string) int {
+	for i, f := range rc.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Disclose returns the disclosure of the fields @names of the record, which must be a
+// leaf of the tree @outer.
+func (rc RecordContent) Disclose(outer *MerkleTree, names ...string) (*Disclosure, error) {
+	inner, err := rc.Tree()
+	if err != nil {
+		return nil, err
+	}
+	merklePath, index, err := outer.GetMerklePath(rc)
+	if err != nil {
+		return nil, err
+	}
+	if merklePath == nil {
+		return nil, errors.New("error: record not in tree")
+	}
+	d := &Disclosure{
+		RecordRoot: inner.MerkleRoot,
+		MerklePath: merklePath,
+		Index:      index,
+	}
+	for _, name := range names {
+		i := rc.field(name)
+		if i < 0 {
+			return nil, errors.New("error: field not in record " + name)
+		}
+		fieldPath, fieldIndex, err := inner.GetMerklePathAt(i)
+		if err != nil {
+			return nil, err
+		}
+		d.Fields = append(d.Fields, DisclosedField{
+			RecordField: rc.Fields[i],
+			MerklePath:  fieldPath,
+			Index:       fieldIndex,
+		})
+	}
+	return d, nil
+}
+
+// VerifyDisclosure returns true if the disclosed fields of @d are proven against the root
+// of their record, and the record against the root @root of the outer tree with the hash
+// strategy @hashStrategy. The inner tree of a record always uses "sha256".
+func VerifyDisclosure(root []byte, d *Disclosure, hashStrategy string) (bool, error) {
+	if d == nil {
+		return false, errors.New("error: no proof")
+	}
+	ok, err := VerifyMerklePath(d.RecordRoot, root, d.MerklePath, d.Index, hashStrategy)
+	if err != nil || !ok {
+		return false, err
+	}
+	for _, f := range d.Fields {
+		leafHash, err := f.leaf().CalculateHash()
+		if err != nil {
+			return false, err
+		}
+		ok, err := VerifyMerklePath(leafHash, d.RecordRoot, f.MerklePath, f.Index, recordHashStrategy)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
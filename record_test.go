@@ -0,0 +1,191 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type testTrade struct {
+	Pair   string  `json:"pair"`
+	Price  float64 `json:"price"`
+	Volume int     `json:"volume"`
+	Trader string  `json:"trader"`
+}
+
+// tradeTree returns a tree of trade records and the records.
+func tradeTree(t *testing.T, salt SaltFunc) (*MerkleTree, []RecordContent) {
+	trades := []interface{}{
+		testTrade{"btc-usd", 42000.5, 3, "alice"},
+		testTrade{"eth-usd", 3000, 10, "bob"},
+		map[string]interface{}{"pair": "btc-usd", "price": 42001, "volume": 1, "trader": "carol"},
+	}
+	var cs []Content
+	var records []RecordContent
+	for _, trade := range trades {
+		rc, err := NewRecordContent(trade, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rc)
+		cs = append(cs, rc)
+	}
+	tree, err := NewTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, records
+}
+
+func TestRecordContent_Disclose(t *testing.T) {
+	tree, records := tradeTree(t, RandomSalt())
+	for i, rc := range records {
+		d, err := rc.Disclose(tree, "pair", "price")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyDisclosure(tree.MerkleRoot, d, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid disclosure", i)
+		}
+		if len(d.Fields) != 2 || d.Fields[0].Name != "pair" || d.Fields[1].Name != "price" {
+			t.Fatalf("[case:%d] error: unexpected disclosed fields %v", i, d.Fields)
+		}
+		var pair string
+		if err := json.Unmarshal(d.Fields[0].Value, &pair); err != nil {
+			t.Fatal(err)
+		}
+		if pair != "btc-usd" && pair != "eth-usd" {
+			t.Errorf("[case:%d] error: unexpected pair %s", i, pair)
+		}
+
+		// Undisclosed fields are not part of the disclosure.
+		data, _ := json.Marshal(d)
+		if bytes.Contains(data, []byte("trader")) || bytes.Contains(data, []byte("volume")) {
+			t.Errorf("[case:%d] error: disclosure reveals undisclosed fields", i)
+		}
+
+		// Tampered values or another root do not verify.
+		tampered := *d
+		tampered.Fields = append([]DisclosedField{}, d.Fields...)
+		tampered.Fields[1].Value = json.RawMessage("1")
+		if ok, _ := VerifyDisclosure(tree.MerkleRoot, &tampered, "sha256"); ok {
+			t.Errorf("[case:%d] error: expected tampered disclosure to be invalid", i)
+		}
+		if ok, _ := VerifyDisclosure(d.RecordRoot, d, "sha256"); ok {
+			t.Errorf("[case:%d] error: expected disclosure to be invalid against another root", i)
+		}
+	}
+	if _, err := records[0].Disclose(tree, "fee"); err == nil {
+		t.Errorf("error: expected error for unknown field")
+	}
+	if _, err := VerifyDisclosure(tree.MerkleRoot, nil, "sha256"); err == nil {
+		t.Errorf("error: expected error for nil disclosure")
+	}
+}
+
+func TestRecordContent_DiscloseUnsorted(t *testing.T) {
+	rc, err := NewRecordContent(testTrade{"btc-usd", 42000.5, 3, "alice"}, RandomSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields in reverse order, as they may come from another encoder.
+	for i, j := 0, len(rc.Fields)-1; i < j; i, j = i+1, j-1 {
+		rc.Fields[i], rc.Fields[j] = rc.Fields[j], rc.Fields[i]
+	}
+	tree, err := NewTreeWithHashStrategy([]Content{rc, ByteContent{Content: []byte("other")}}, "keccak256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range rc.Fields {
+		d, err := rc.Disclose(tree, f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Fields[0].Name != f.Name || !bytes.Equal(d.Fields[0].Value, f.Value) {
+			t.Errorf("[field:%s] error: disclosed field %s", f.Name, d.Fields[0].Name)
+		}
+		ok, err := VerifyDisclosure(tree.MerkleRoot, d, "keccak256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[field:%s] error: expected valid disclosure", f.Name)
+		}
+		if ok, _ := VerifyDisclosure(tree.MerkleRoot, d, "sha256"); ok {
+			t.Errorf("[field:%s] error: expected disclosure to be invalid with another hash strategy", f.Name)
+		}
+	}
+}
+
+func TestRecordContent_StorageBucket(t *testing.T) {
+	sb := StorageBucket{
+		Content:   []byte("trade data"),
+		Topic:     "trades",
+		Size:      10,
+		ID:        "bucket-1",
+		Timestamp: time.Unix(1600000000, 0).UTC(),
+	}
+	rc, err := NewRecordContent(sb, HMACSalt([]byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := NewTree([]Content{rc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := rc.Disclose(tree, "Topic", "Timestamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyDisclosure(tree.MerkleRoot, d, "sha256"); err != nil || !ok {
+		t.Errorf("error: expected valid disclosure, got %v, %v", ok, err)
+	}
+	if data, _ := json.Marshal(d); bytes.Contains(data, []byte("bucket-1")) {
+		t.Errorf("error: disclosure reveals undisclosed fields")
+	}
+}
+
+func TestRecordContent_Canonical(t *testing.T) {
+	salt := HMACSalt([]byte("secret"))
+	a, err := NewRecordContent(testTrade{"btc-usd", 42000.5, 3, "alice"}, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewRecordContent(map[string]interface{}{"trader": "alice", "volume": 3, "price": 42000.5, "pair": "btc-usd"}, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ha, _ := a.CalculateHash()
+	hb, _ := b.CalculateHash()
+	if !bytes.Equal(ha, hb) {
+		t.Errorf("error: expected struct and map with equal fields to have the same hash")
+	}
+	if _, err := NewRecordContent(42, salt); err == nil {
+		t.Errorf("error: expected error for a record that is not a struct or map")
+	}
+	if _, err := NewRecordContent(map[string]int{}, salt); err == nil {
+		t.Errorf("error: expected error for a record without fields")
+	}
+}
+
+func TestRecordContent_JSON(t *testing.T) {
+	tree, records := tradeTree(t, RandomSalt())
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored MerkleTree
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	for i, rc := range records {
+		if ok, _ := rc.Equals(restored.Leafs[i].C); !ok {
+			t.Errorf("[case:%d] error: expected unmarshaled record to equal %v", i, rc)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package merkletree
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned for writes and seals of a closed PoolRotator.
+var ErrClosed = errors.New("rotator error. rotator is closed")
+
+// SealedTree is the tree built from a sealed BucketPool.
+type SealedTree struct {
+	Topic  string
+	Tree   *MerkleTree
+	Sealed time.Time
+}
+
+// SealTo returns a callback for NewPoolRotator handing sealed trees to the channel @ch.
+func SealTo(ch chan<- SealedTree) func(SealedTree) {
+	return func(st SealedTree) {
+		ch <- st
+	}
+}
+
+// PoolRotator manages the BucketPool of a topic. It fills the buckets of the pool in
+// order and seals the pool when it is exhausted or after a time interval: the tree of the
+// pool is built by MakeTree, handed to a callback, and a fresh pool is started. It is safe
+// for concurrent use. Sealed trees are handed to the callback one at a time in the order
+// they were sealed, so the callback must not write to or seal the rotator itself.
+type PoolRotator struct {
+	mu sync.Mutex
+	// handMu serializes the callback. It is acquired while mu is held, so trees are
+	// handed over in the order they were sealed.
+	handMu    sync.Mutex
+	closeOnce sync.Once
+	// closed is set by Close. It is guarded by mu.
+	closed bool
+	topic  string
+	maxNum uint64
+	size   uint64
+	writer *BucketWriter
+	onSeal func(SealedTree)
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewPoolRotator creates a PoolRotator for pools of @maxNum buckets of @size bytes of the
+// topic @topic. Sealed trees are passed to @onSeal. If @interval is positive, the pool is
+// also sealed every @interval, provided anything was written to it.
+func NewPoolRotator(maxNum uint64, size uint64, topic string, interval time.Duration, onSeal func(SealedTree)) *PoolRotator {
+	return newPoolRotator(NewBucketWriter(NewBucketPool(maxNum, size, topic)), maxNum, size, topic, interval, onSeal)
+}
+
+// RecoverPoolRotator creates a PoolRotator like NewPoolRotator, which records its writes
+// and seals in the write-ahead log @wal. The items written since the last seal recorded in
+// @wal are restored into the current pool, see RecoverBucketWriter. The log is closed by
+// Close.
+func RecoverPoolRotator(wal *PoolWAL, maxNum uint64, size uint64, topic string, interval time.Duration, onSeal func(SealedTree)) (*PoolRotator, error) {
+	w, err := RecoverBucketWriter(wal, NewBucketPool(maxNum, size, topic))
+	if err != nil {
+		return nil, err
+	}
+	return newPoolRotator(w, maxNum, size, topic, interval, onSeal), nil
+}
+
+// newPoolRotator creates a PoolRotator writing with @w.
+func newPoolRotator(w *BucketWriter, maxNum uint64, size uint64, topic string, interval time.Duration, onSeal func(SealedTree)) *PoolRotator {
+	r := &PoolRotator{
+		topic:  topic,
+		maxNum: maxNum,
+		size:   size,
+		writer: w,
+		onSeal: onSeal,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if interval > 0 {
+		go r.run(interval)
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// run seals the pool every @interval until the rotator is closed.
+func (r *PoolRotator) run(interval time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.Seal()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Topic returns the topic of the rotator.
+func (r *PoolRotator) Topic() string {
+	return r.topic
+}
+
+// Write appends @bs to the current bucket. If the bucket is full, the next bucket of the
+// pool is used, and if the pool is exhausted, it is sealed and a fresh pool is started.
+func (r *PoolRotator) Write(bs []byte) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	var sealed []SealedTree
+	err := r.writer.Write(bs)
+	if err == ErrPoolExhausted {
+		var st *SealedTree
+		if st, err = r.seal(); err == nil {
+			if st != nil {
+				sealed = append(sealed, *st)
+			}
+			err = r.writer.Write(bs)
+		}
+	}
+	r.unlockAndHandOver(sealed)
+	return err
+}
+
+// seal builds the tree of the current pool and starts a fresh pool. Returns nil if
+// nothing was written to the pool. The lock must be held.
+func (r *PoolRotator) seal() (*SealedTree, error) {
+	if !r.writer.Written() {
+		return nil, nil
+	}
+	tree, err := r.writer.Seal(NewBucketPool(r.maxNum, r.size, r.topic))
+	if err != nil {
+		return nil, err
+	}
+	return &SealedTree{
+		Topic:  r.topic,
+		Tree:   tree,
+		Sealed: time.Now(),
+	}, nil
+}
+
+// unlockAndHandOver releases the lock and passes the trees @sealed to the callback. The
+// lock must be held.
+func (r *PoolRotator) unlockAndHandOver(sealed []SealedTree) {
+	if r.onSeal == nil || len(sealed) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	r.handMu.Lock()
+	defer r.handMu.Unlock()
+	r.mu.Unlock()
+	for _, st := range sealed {
+		r.onSeal(st)
+	}
+}
+
+// Seal seals the current pool if anything was written to it, hands its tree to the
+// callback and starts a fresh pool.
+func (r *PoolRotator) Seal() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	st, err := r.seal()
+	if err != nil || st == nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.unlockAndHandOver([]SealedTree{*st})
+	return nil
+}
+
+// Close stops sealing by time, seals the current pool and closes the write-ahead log.
+// Afterwards, Write and Seal return ErrClosed. Closing a closed rotator does nothing. If
+// the pool cannot be sealed, the rotator stays open and Close may be retried.
+func (r *PoolRotator) Close() error {
+	r.closeOnce.Do(func() { close(r.stop) })
+	<-r.done
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	st, err := r.seal()
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.closed = true
+	if r.writer.wal != nil {
+		err = r.writer.wal.Close()
+	}
+	var sealed []SealedTree
+	if st != nil {
+		sealed = append(sealed, *st)
+	}
+	r.unlockAndHandOver(sealed)
+	return err
+}
@@ -0,0 +1,190 @@
+package merkletree
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolRotator_SealWhenFull(t *testing.T) {
+	var sealed []SealedTree
+	// 3 buckets of 32 bytes hold two items of 8 bytes each.
+	r := NewPoolRotator(3, 32, "trades", 0, func(st SealedTree) {
+		sealed = append(sealed, st)
+	})
+	var items []string
+	for i := 0; i < 14; i++ {
+		item := fmt.Sprintf("item-%03d", i)
+		items = append(items, item)
+		if err := r.Write([]byte(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sealed) != 2 {
+		t.Fatalf("error: expected 2 sealed trees got %d", len(sealed))
+	}
+	if err := r.Write(make([]byte, 25)); err != ErrItemTooLarge {
+		t.Errorf("error: expected ErrItemTooLarge got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != 3 {
+		t.Fatalf("error: expected 3 sealed trees after close got %d", len(sealed))
+	}
+	for _, item := range items {
+		var found bool
+		for _, st := range sealed {
+			if st.Topic != "trades" {
+				t.Errorf("error: unexpected topic %s", st.Topic)
+			}
+			ok, _, err := DataInStorageTree([]byte(item), *st.Tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found = found || ok
+		}
+		if !found {
+			t.Errorf("error: item %s not in any sealed tree", item)
+		}
+	}
+}
+
+func TestPoolRotator_SealByInterval(t *testing.T) {
+	ch := make(chan SealedTree, 10)
+	r := NewPoolRotator(10, 64, "rates", 10*time.Millisecond, SealTo(ch))
+	defer r.Close()
+	if err := r.Write([]byte("0.05")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case st := <-ch:
+		ok, _, err := DataInStorageTree([]byte("0.05"), *st.Tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("error: expected item in sealed tree")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error: expected pool to be sealed by interval")
+	}
+	select {
+	case <-ch:
+		t.Errorf("error: expected empty pool not to be sealed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPoolRotator_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	var trees []*MerkleTree
+	r := NewPoolRotator(4, 64, "trades", time.Millisecond, func(st SealedTree) {
+		mu.Lock()
+		trees = append(trees, st.Tree)
+		mu.Unlock()
+	})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if err := r.Write([]byte(fmt.Sprintf("%d-%d", w, i))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for _, tree := range trees {
+		for _, leaf := range tree.Leafs {
+			if leaf.Dup {
+				continue
+			}
+			sb, _ := asStorageBucket(leaf.C)
+			content, err := sb.ReadContent()
+			if err != nil {
+				t.Fatal(err)
+			}
+			count += len(content)
+		}
+	}
+	if count != 200 {
+		t.Errorf("error: expected 200 items in sealed trees got %d", count)
+	}
+}
+
+func TestPoolRotator_HandOverOrder(t *testing.T) {
+	var sealed []time.Time
+	// The callback is never called concurrently, so it needs no lock.
+	r := NewPoolRotator(1, 16, "trades", 0, func(st SealedTree) {
+		sealed = append(sealed, st.Sealed)
+		time.Sleep(time.Millisecond)
+	})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if err := r.Write([]byte(fmt.Sprintf("%d-%d", w, i))); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// Concurrent calls of Close do not close the stop channel twice.
+	var errs [2]error
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = r.Close()
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("[close:%d] error: unexpected error %v", i, err)
+		}
+	}
+	if len(sealed) != 40 {
+		t.Errorf("error: expected 40 sealed trees got %d", len(sealed))
+	}
+	for i := 1; i < len(sealed); i++ {
+		if sealed[i].Before(sealed[i-1]) {
+			t.Errorf("[tree:%d] error: expected trees in the order they were sealed", i)
+		}
+	}
+}
+
+func TestPoolRotator_Close(t *testing.T) {
+	var sealed int
+	r := NewPoolRotator(2, 32, "trades", time.Hour, func(st SealedTree) { sealed++ })
+	if err := r.Write([]byte("item-0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write([]byte("item-1")); err != ErrClosed {
+		t.Errorf("error: expected ErrClosed for write after close got %v", err)
+	}
+	if err := r.Seal(); err != ErrClosed {
+		t.Errorf("error: expected ErrClosed for seal after close got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("error: expected second close to succeed got %v", err)
+	}
+	if sealed != 1 {
+		t.Errorf("error: expected 1 sealed tree got %d", sealed)
+	}
+}
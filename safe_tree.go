@@ -0,0 +1,124 @@
+package merkletree
+
+import (
+	"sync"
+)
+
+// SafeTree wraps a MerkleTree for concurrent use. Mutations are serialized, while any
+// number of readers may verify content and generate proofs concurrently. The wrapped
+// tree must not be accessed directly while it is held by a SafeTree.
+type SafeTree struct {
+	mu   sync.RWMutex
+	tree *MerkleTree
+}
+
+// NewSafeTree creates a new SafeTree using the content cs.
+func NewSafeTree(cs []Content) (*SafeTree, error) {
+	t, err := NewTree(cs)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeTree{tree: t}, nil
+}
+
+// NewSafeTreeWithHashStrategy creates a new SafeTree using the content cs and the hash
+// strategy @hashStrategy.
+func NewSafeTreeWithHashStrategy(cs []Content, hashStrategy string) (*SafeTree, error) {
+	t, err := NewTreeWithHashStrategy(cs, hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return &SafeTree{tree: t}, nil
+}
+
+// MerkleRoot returns a copy of the current root hash of the tree.
+func (st *SafeTree) MerkleRoot() []byte {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return append([]byte{}, st.tree.MerkleRoot...)
+}
+
+// HashStrategy returns the hash strategy of the tree.
+func (st *SafeTree) HashStrategy() string {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.HashStrategy
+}
+
+// Len returns the number of leafs of the tree, not counting the duplicate of the last leaf.
+func (st *SafeTree) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.LeafCount()
+}
+
+// GetMerklePath gets Merkle path and indexes (left leaf or right leaf) of @content,
+// see MerkleTree.GetMerklePath.
+func (st *SafeTree) GetMerklePath(content Content) ([][]byte, []int64, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.GetMerklePath(content)
+}
+
+// Prove returns the Merkle path and indexes of @content along with the root they lead to.
+// As the tree may change between calls to MerkleRoot and GetMerklePath, Prove should be
+// used whenever a proof is published together with its root.
+func (st *SafeTree) Prove(content Content) ([]byte, [][]byte, []int64, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	merklePath, index, err := st.tree.GetMerklePath(content)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return append([]byte{}, st.tree.MerkleRoot...), merklePath, index, nil
+}
+
+// VerifyContent indicates whether @content is in the tree and the hashes are valid for
+// it, see MerkleTree.VerifyContent.
+func (st *SafeTree) VerifyContent(content Content) (bool, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.VerifyContent(content)
+}
+
+// VerifyTree validates the hashes at each level of the tree, see MerkleTree.VerifyTree.
+func (st *SafeTree) VerifyTree() (bool, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.VerifyTree()
+}
+
+// String returns a string representation of the tree.
+func (st *SafeTree) String() string {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.String()
+}
+
+// ExtendTree extends the tree by the content @cs, see MerkleTree.ExtendTree.
+func (st *SafeTree) ExtendTree(cs []Content) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.tree.ExtendTree(cs)
+}
+
+// RebuildTree rebuilds the tree from the content of its leafs, see MerkleTree.RebuildTree.
+func (st *SafeTree) RebuildTree() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.tree.RebuildTree()
+}
+
+// RebuildTreeWith replaces the content of the tree, see MerkleTree.RebuildTreeWith.
+func (st *SafeTree) RebuildTreeWith(cs []Content) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.tree.RebuildTreeWith(cs)
+}
+
+// Read calls @f with the wrapped tree while holding the read lock. @f must not modify the tree.
+func (st *SafeTree) Read(f func(t *MerkleTree) error) error {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return f(st.tree)
+}
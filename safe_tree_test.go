@@ -0,0 +1,113 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSafeTree_Concurrent(t *testing.T) {
+	initial := []Content{TestSHA256Content{x: "0"}}
+	st, err := NewSafeTree(initial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const writers, appends, readers = 4, 25, 8
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < appends; i++ {
+				c := TestSHA256Content{x: fmt.Sprintf("%d-%d", w, i)}
+				if err := st.ExtendTree([]Content{c}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	var readersWg sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		readersWg.Add(1)
+		go func() {
+			defer readersWg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				root, merklePath, index, err := st.Prove(initial[0])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				leafHash, _ := initial[0].CalculateHash()
+				ok, err := VerifyMerklePath(leafHash, root, merklePath, index, st.HashStrategy())
+				if err != nil || !ok {
+					t.Errorf("error: expected valid proof against concurrent root, got %v %v", ok, err)
+					return
+				}
+				if ok, err := st.VerifyContent(initial[0]); err != nil || !ok {
+					t.Errorf("error: expected content to verify, got %v %v", ok, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readersWg.Wait()
+
+	ok, err := st.VerifyTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("error: expected valid tree")
+	}
+	for w := 0; w < writers; w++ {
+		for i := 0; i < appends; i++ {
+			c := TestSHA256Content{x: fmt.Sprintf("%d-%d", w, i)}
+			if ok, _ := st.VerifyContent(c); !ok {
+				t.Errorf("error: expected %v in tree", c)
+			}
+		}
+	}
+}
+
+func TestSafeTree_RebuildTreeWith(t *testing.T) {
+	st, err := NewSafeTree(table[0].contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.RebuildTreeWith(table[1].contents); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(st.MerkleRoot(), table[1].expectedHash) {
+		t.Errorf("error: expected hash equal to %v got %v", table[1].expectedHash, st.MerkleRoot())
+	}
+	if err := st.RebuildTreeWith(nil); err == nil {
+		t.Errorf("error: expected error for empty content")
+	}
+}
+
+func TestSafeTree_Len(t *testing.T) {
+	for n := 1; n <= 4; n++ {
+		var cs []Content
+		for i := 0; i < n; i++ {
+			cs = append(cs, TestSHA256Content{x: fmt.Sprintf("leaf %d", i)})
+		}
+		st, err := NewSafeTree(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Len() != n {
+			t.Errorf("[case:%d] error: expected %d leafs got %d", n, n, st.Len())
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+// -----------------------------------------------------------------------
+// Salted leaves
+// -----------------------------------------------------------------------
+
+// SaltSize is the size of the salts created by RandomSalt and HMACSalt.
+const SaltSize = 32
+
+// SaltedContent implements Content for a content mixed with a salt. As the salt is part of
+// the leaf hash, the hash of a sibling in a Merkle path reveals nothing about the sibling's
+// content, even if it could be guessed.
+type SaltedContent struct {
+	Content Content
+	Salt    []byte
+}
+
+// Custom marshaler for SaltedContent type
+func (sc SaltedContent) MarshalJSON() ([]byte, error) {
+	content, err := json.Marshal(sc.Content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Type    string `json:"_type"`
+		Content json.RawMessage
+		Salt    []byte
+	}{
+		Type:    "SaltedContent",
+		Content: content,
+		Salt:    sc.Salt,
+	})
+}
+
+// UnmarshalJSON is a custom unmarshaler for SaltedContent, which restores the type of the
+// salted content by its _type, see newContent.
+func (sc *SaltedContent) UnmarshalJSON(data []byte) error {
+	var out struct {
+		Content json.RawMessage
+		Salt    []byte
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	var _type struct {
+		Type string `json:"_type"`
+	}
+	if err := json.Unmarshal(out.Content, &_type); err != nil {
+		return err
+	}
+	newC, ok := newContent[_type.Type]
+	if !ok {
+		return errors.New("error: unknown content type " + _type.Type)
+	}
+	c := newC()
+	if err := json.Unmarshal(out.Content, c); err != nil {
+		return err
+	}
+	sc.Content = c
+	sc.Salt = out.Salt
+	return nil
+}
+
+// CalculateHash calculates the hash of a SaltedContent, which is the SHA-256 hash of
+// the length of the salt (8 bytes, big endian), the salt and the hash of the content.
+// Returns an error if there is no content.
+func (sc SaltedContent) CalculateHash() ([]byte, error) {
+	if sc.Content == nil {
+		return nil, errors.New("error: salted content without content")
+	}
+	contentHash, err := sc.Content.CalculateHash()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(sc.Salt)))
+	if _, err := h.Write(buf[:]); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(sc.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(contentHash); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Equals returns true if two SaltedContents have equal content and salt, false otherwise.
+// Returns an error if either has no content.
+func (sc SaltedContent) Equals(other Content) (bool, error) {
+	var o SaltedContent
+	switch oc := other.(type) {
+	case SaltedContent:
+		o = oc
+	case *SaltedContent:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	if sc.Content == nil || o.Content == nil {
+		return false, errors.New("error: salted content without content")
+	}
+	if !bytes.Equal(sc.Salt, o.Salt) {
+		return false, nil
+	}
+	return sc.Content.Equals(o.Content)
+}
+
+// SaltFunc returns the salt of the content @c at the leaf @index.
+type SaltFunc func(index int, c Content) ([]byte, error)
+
+// RandomSalt returns a SaltFunc creating random salts. The salts must be stored to prove
+// the content later, see SaltedTree.
+func RandomSalt() SaltFunc {
+	return func(index int, c Content) ([]byte, error) {
+		salt := make([]byte, SaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return salt, nil
+	}
+}
+
+// HMACSalt returns a SaltFunc deriving salts from the secret @key as HMAC-SHA256 of the
+// leaf index (8 bytes, big endian) and the hash of the content. The salts can be derived
+// again by the holder of @key, so they need not be stored.
+func HMACSalt(key []byte) SaltFunc {
+	return func(index int, c Content) ([]byte, error) {
+		contentHash, err := c.CalculateHash()
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(sha256.New, key)
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(index))
+		mac.Write(buf[:])
+		mac.Write(contentHash)
+		return mac.Sum(nil), nil
+	}
+}
+
+// SaltedTree is a Merkle tree of salted leafs. Its leafs hold the SaltedContents, so
+// the salts are stored alongside the content.
+type SaltedTree struct {
+	Tree *MerkleTree
+}
+
+// NewSaltedTree creates a SaltedTree from the content @cs, salted by @salt.
+func NewSaltedTree(cs []Content, salt SaltFunc) (*SaltedTree, error) {
+	salted := make([]Content, len(cs))
+	for i, c := range cs {
+		s, err := salt(i, c)
+		if err != nil {
+			return nil, err
+		}
+		salted[i] = SaltedContent{Content: c, Salt: s}
+	}
+	tree, err := NewTree(salted)
+	if err != nil {
+		return nil, err
+	}
+	return &SaltedTree{Tree: tree}, nil
+}
+
+// MerkleRoot returns the root hash of the tree.
+func (st *SaltedTree) MerkleRoot() []byte {
+	return st.Tree.MerkleRoot
+}
+
+// Leaf returns the salted content of the leaf at @index.
+func (st *SaltedTree) Leaf(index int) (SaltedContent, error) {
+	if index < 0 || index >= len(st.Tree.Leafs) || st.Tree.Leafs[index].Dup {
+		return SaltedContent{}, errors.New("error: leaf index out of range")
+	}
+	switch sc := st.Tree.Leafs[index].C.(type) {
+	case SaltedContent:
+		return sc, nil
+	case *SaltedContent:
+		if sc != nil {
+			return *sc, nil
+		}
+	}
+	return SaltedContent{}, errors.New("error: tree leaf is not a SaltedContent")
+}
+
+// SaltedProof proves a content of a SaltedTree. It holds the salt of the proven leaf only.
+type SaltedProof struct {
+	Leaf       SaltedContent
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// Prove returns the proof of the leaf at @index.
+func (st *SaltedTree) Prove(index int) (*SaltedProof, error) {
+	leaf, err := st.Leaf(index)
+	if err != nil {
+		return nil, err
+	}
+	merklePath, idx, err := st.Tree.GetMerklePathAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return &SaltedProof{
+		Leaf:       leaf,
+		MerklePath: merklePath,
+		Index:      idx,
+	}, nil
+}
+
+// VerifySaltedProof returns true if @proof proves its content against the root @root of a
+// tree with the hash strategy @hashStrategy.
+func VerifySaltedProof(root []byte, proof *SaltedProof, hashStrategy string) (bool, error) {
+	leafHash, err := proof.Leaf.CalculateHash()
+	if err != nil {
+		return false, err
+	}
+	return VerifyMerklePath(leafHash, root, proof.MerklePath, proof.Index, hashStrategy)
+}
@@ -0,0 +1,141 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// rateContents returns low-entropy contents, such as interest rates.
+func rateContents() []Content {
+	var cs []Content
+	for _, rate := range []string{"0.05", "0.10", "0.15", "0.20", "0.25"} {
+		cs = append(cs, TestSHA256Content{x: rate})
+	}
+	return cs
+}
+
+func TestSaltedTree_Prove(t *testing.T) {
+	cs := rateContents()
+	for _, salt := range []SaltFunc{RandomSalt(), HMACSalt([]byte("secret"))} {
+		st, err := NewSaltedTree(cs, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range cs {
+			proof, err := st.Prove(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := VerifySaltedProof(st.MerkleRoot(), proof, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[case:%d] error: expected valid proof", i)
+			}
+			if len(proof.Leaf.Salt) != SaltSize {
+				t.Errorf("[case:%d] error: expected salt of %d bytes got %d", i, SaltSize, len(proof.Leaf.Salt))
+			}
+
+			// No sibling hash can be matched to a guessed unsalted content.
+			for _, sibling := range proof.MerklePath {
+				for _, guess := range cs {
+					hash, _ := guess.CalculateHash()
+					if bytes.Equal(hash, sibling) {
+						t.Errorf("[case:%d] error: sibling hash reveals content %v", i, guess)
+					}
+				}
+			}
+
+			// A proof with another salt does not verify.
+			tampered := *proof
+			tampered.Leaf.Salt = append([]byte{}, proof.Leaf.Salt...)
+			tampered.Leaf.Salt[0] ^= 1
+			if ok, _ := VerifySaltedProof(st.MerkleRoot(), &tampered, "sha256"); ok {
+				t.Errorf("[case:%d] error: expected proof with wrong salt to be invalid", i)
+			}
+		}
+		if _, err := st.Prove(len(cs)); err == nil {
+			t.Errorf("error: expected error for the duplicate leaf")
+		}
+	}
+}
+
+func TestSaltedContent_NoContent(t *testing.T) {
+	sc := SaltedContent{Salt: []byte("salt")}
+	if _, err := sc.CalculateHash(); err == nil {
+		t.Errorf("error: expected error for hash without content")
+	}
+	other := SaltedContent{Content: TestSHA256Content{x: "a"}, Salt: []byte("salt")}
+	if _, err := sc.Equals(other); err == nil {
+		t.Errorf("error: expected error for comparison without content")
+	}
+	if _, err := other.Equals(&sc); err == nil {
+		t.Errorf("error: expected error for comparison with content without content")
+	}
+	if _, err := VerifySaltedProof([]byte("root"), &SaltedProof{Leaf: sc}, "sha256"); err == nil {
+		t.Errorf("error: expected error for proof without content")
+	}
+}
+
+func TestSaltedTree_Salts(t *testing.T) {
+	cs := rateContents()
+	roots := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		st, err := NewSaltedTree(cs, RandomSalt())
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots[string(st.MerkleRoot())] = true
+	}
+	if len(roots) != 2 {
+		t.Errorf("error: expected random salts to yield different roots")
+	}
+
+	st1, _ := NewSaltedTree(cs, HMACSalt([]byte("secret")))
+	st2, _ := NewSaltedTree(cs, HMACSalt([]byte("secret")))
+	st3, _ := NewSaltedTree(cs, HMACSalt([]byte("other")))
+	if !bytes.Equal(st1.MerkleRoot(), st2.MerkleRoot()) {
+		t.Errorf("error: expected HMAC salts to be derived again")
+	}
+	if bytes.Equal(st1.MerkleRoot(), st3.MerkleRoot()) {
+		t.Errorf("error: expected HMAC salts to depend on the key")
+	}
+	l0, _ := st1.Leaf(0)
+	l1, _ := st1.Leaf(1)
+	if bytes.Equal(l0.Salt, l1.Salt) {
+		t.Errorf("error: expected salts to differ per leaf")
+	}
+}
+
+func TestSaltedTree_JSON(t *testing.T) {
+	var cs []Content
+	for i := 0; i < 3; i++ {
+		cs = append(cs, ByteContent{Content: []byte(fmt.Sprintf("rate-%d", i))})
+	}
+	st, err := NewSaltedTree(cs, RandomSalt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(st.Tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree MerkleTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatal(err)
+	}
+	restored := SaltedTree{Tree: &tree}
+	for i := range cs {
+		leaf, err := restored.Leaf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, _ := st.Leaf(i)
+		if ok, _ := original.Equals(leaf); !ok {
+			t.Errorf("[case:%d] error: expected %v got %v", i, original, leaf)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// pnode is an immutable node of a Snapshot. Nodes are shared between snapshots, so they
+// must never be modified once created. A nil right child stands for a duplicate of the
+// left child, like the duplicated last node of a MerkleTree level.
+type pnode struct {
+	left  *pnode
+	right *pnode
+	hash  []byte
+	c     Content
+}
+
+// Snapshot is an immutable, structurally shared version of a Merkle tree. Append and
+// Update return a new Snapshot and copy only the nodes on the changed path, so older
+// snapshots stay valid and cheap to keep. The root of a Snapshot equals the MerkleRoot of
+// a MerkleTree built from the same content with the same hash strategy.
+type Snapshot struct {
+	root         *pnode
+	size         int
+	depth        int
+	hashStrategy string
+}
+
+// NewSnapshot creates a new Snapshot from the content @cs using the default hash strategy.
+func NewSnapshot(cs []Content) (*Snapshot, error) {
+	return NewSnapshotWithHashStrategy(cs, "sha256")
+}
+
+// NewSnapshotWithHashStrategy creates a new Snapshot from the content @cs using the hash
+// strategy @hashStrategy.
+func NewSnapshotWithHashStrategy(cs []Content, hashStrategy string) (*Snapshot, error) {
+	if len(cs) == 0 {
+		return nil, errors.New("error: cannot construct tree with no content")
+	}
+	if _, ok := GetHashStrategies()[hashStrategy]; !ok {
+		return nil, fmt.Errorf("error: unknown hash strategy %s", hashStrategy)
+	}
+	leaf, err := newLeaf(cs[0])
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{depth: 1, size: 1, hashStrategy: hashStrategy}
+	if s.root, err = s.newNode(leaf, nil); err != nil {
+		return nil, err
+	}
+	return s.Append(cs[1:]...)
+}
+
+// newLeaf returns the leaf node of @c.
+func newLeaf(c Content) (*pnode, error) {
+	hash, err := c.CalculateHash()
+	if err != nil {
+		return nil, err
+	}
+	return &pnode{hash: hash, c: c}, nil
+}
+
+// newNode returns the intermediate node with the children @left and @right.
+func (s *Snapshot) newNode(left, right *pnode) (*pnode, error) {
+	h := GetHashStrategies()[s.hashStrategy]
+	rightHash := left.hash
+	if right != nil {
+		rightHash = right.hash
+	}
+	if _, err := h.Write(concatHashes(left.hash, rightHash)); err != nil {
+		return nil, err
+	}
+	return &pnode{left: left, right: right, hash: h.Sum(nil)}, nil
+}
+
+// set returns a copy of the subtree @n of height @height with the leaf at @index
+// replaced by @leaf. A nil @n is an empty subtree, which is created on the way.
+func (s *Snapshot) set(n *pnode, height int, index int, leaf *pnode) (*pnode, error) {
+	if height == 0 {
+		return leaf, nil
+	}
+	half := 1 << uint(height-1)
+	var left, right *pnode
+	if n != nil {
+		left, right = n.left, n.right
+	}
+	var err error
+	if index < half {
+		left, err = s.set(left, height-1, index, leaf)
+	} else {
+		right, err = s.set(right, height-1, index-half, leaf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.newNode(left, right)
+}
+
+// MerkleRoot returns the root hash of the snapshot.
+func (s *Snapshot) MerkleRoot() []byte {
+	return s.root.hash
+}
+
+// HashStrategy returns the hash strategy of the snapshot.
+func (s *Snapshot) HashStrategy() string {
+	return s.hashStrategy
+}
+
+// Len returns the number of leafs of the snapshot.
+func (s *Snapshot) Len() int {
+	return s.size
+}
+
+// Append returns a new Snapshot with the content @cs appended. The snapshot @s is unchanged.
+func (s *Snapshot) Append(cs ...Content) (*Snapshot, error) {
+	next := *s
+	for _, c := range cs {
+		leaf, err := newLeaf(c)
+		if err != nil {
+			return nil, err
+		}
+		if next.size == 1<<uint(next.depth) {
+			// The tree is full, the old root becomes the left child of a new root.
+			if next.root, err = next.newNode(next.root, nil); err != nil {
+				return nil, err
+			}
+			next.depth++
+		}
+		if next.root, err = next.set(next.root, next.depth, next.size, leaf); err != nil {
+			return nil, err
+		}
+		next.size++
+	}
+	return &next, nil
+}
+
+// Update returns a new Snapshot with the leaf at @index replaced by @c. The snapshot @s
+// is unchanged.
+func (s *Snapshot) Update(index int, c Content) (*Snapshot, error) {
+	if index < 0 || index >= s.size {
+		return nil, errors.New("error: leaf index out of range")
+	}
+	leaf, err := newLeaf(c)
+	if err != nil {
+		return nil, err
+	}
+	next := *s
+	if next.root, err = next.set(next.root, next.depth, index, leaf); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// Leaf returns the content of the leaf at @index.
+func (s *Snapshot) Leaf(index int) (Content, error) {
+	if index < 0 || index >= s.size {
+		return nil, errors.New("error: leaf index out of range")
+	}
+	n := s.root
+	for height := s.depth; height > 0; height-- {
+		if index < 1<<uint(height-1) {
+			n = n.left
+		} else {
+			index -= 1 << uint(height-1)
+			n = n.right
+		}
+	}
+	return n.c, nil
+}
+
+// IndexOf returns the index of the first leaf equal to @c, or -1 if there is none.
+func (s *Snapshot) IndexOf(c Content) (int, error) {
+	for i := 0; i < s.size; i++ {
+		leaf, err := s.Leaf(i)
+		if err != nil {
+			return -1, err
+		}
+		ok, err := leaf.Equals(c)
+		if err != nil {
+			return -1, err
+		}
+		if ok {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// GetMerklePath gets the Merkle path and indexes (left leaf or right leaf) of the leaf
+// at @index, in the format of MerkleTree.GetMerklePath.
+func (s *Snapshot) GetMerklePath(index int) ([][]byte, []int64, error) {
+	if index < 0 || index >= s.size {
+		return nil, nil, errors.New("error: leaf index out of range")
+	}
+	merklePath := make([][]byte, s.depth)
+	indexes := make([]int64, s.depth)
+	n := s.root
+	for height := s.depth; height > 0; height-- {
+		half := 1 << uint(height-1)
+		if index < half {
+			sibling := n.right
+			if sibling == nil {
+				sibling = n.left
+			}
+			merklePath[height-1] = sibling.hash
+			indexes[height-1] = 1 // right leaf
+			n = n.left
+		} else {
+			merklePath[height-1] = n.left.hash
+			indexes[height-1] = 0 // left leaf
+			index -= half
+			n = n.right
+		}
+	}
+	return merklePath, indexes, nil
+}
+
+// Tree builds a MerkleTree from the content of the snapshot.
+func (s *Snapshot) Tree() (*MerkleTree, error) {
+	cs := make([]Content, s.size)
+	for i := range cs {
+		c, err := s.Leaf(i)
+		if err != nil {
+			return nil, err
+		}
+		cs[i] = c
+	}
+	return NewTreeWithHashStrategy(cs, s.hashStrategy)
+}
+
+// SnapshotHistory keeps the last published snapshots, such that proofs can be served
+// against any of their roots. It is safe for concurrent use.
+type SnapshotHistory struct {
+	mu        sync.RWMutex
+	max       int
+	snapshots []*Snapshot
+}
+
+// NewSnapshotHistory creates a SnapshotHistory keeping the last @max snapshots.
+// Returns an error if @max is less than 1.
+func NewSnapshotHistory(max int) (*SnapshotHistory, error) {
+	if max < 1 {
+		return nil, errors.New("error: snapshot history must keep at least one snapshot")
+	}
+	return &SnapshotHistory{max: max}, nil
+}
+
+// Publish adds @s as the latest snapshot, dropping the oldest one if the history is full.
+func (h *SnapshotHistory) Publish(s *Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshots = append(h.snapshots, s)
+	if len(h.snapshots) > h.max {
+		h.snapshots = append([]*Snapshot{}, h.snapshots[len(h.snapshots)-h.max:]...)
+	}
+}
+
+// Latest returns the latest published snapshot, or nil if none was published.
+func (h *SnapshotHistory) Latest() *Snapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.snapshots) == 0 {
+		return nil
+	}
+	return h.snapshots[len(h.snapshots)-1]
+}
+
+// Get returns the most recent snapshot with root @root, or nil if it is not in the history.
+func (h *SnapshotHistory) Get(root []byte) *Snapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for i := len(h.snapshots) - 1; i >= 0; i-- {
+		if bytes.Equal(h.snapshots[i].MerkleRoot(), root) {
+			return h.snapshots[i]
+		}
+	}
+	return nil
+}
+
+// Roots returns the roots of the snapshots in the history, oldest first.
+func (h *SnapshotHistory) Roots() [][]byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	roots := make([][]byte, len(h.snapshots))
+	for i, s := range h.snapshots {
+		roots[i] = s.MerkleRoot()
+	}
+	return roots
+}
@@ -0,0 +1,166 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func testContents(n int, prefix string) []Content {
+	var cs []Content
+	for i := 0; i < n; i++ {
+		cs = append(cs, TestSHA256Content{x: fmt.Sprintf("%s%d", prefix, i)})
+	}
+	return cs
+}
+
+func TestSnapshot_MatchesMerkleTree(t *testing.T) {
+	for i := 0; i < len(table); i++ {
+		s, err := NewSnapshot(table[i].contents)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s.MerkleRoot(), table[i].expectedHash) {
+			t.Errorf("[case:%d] error: expected hash equal to %v got %v", table[i].testCaseId, table[i].expectedHash, s.MerkleRoot())
+		}
+	}
+
+	cs := testContents(40, "leaf")
+	s, err := NewSnapshotWithHashStrategy(cs[:1], "sha256d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n <= len(cs); n++ {
+		if n > 1 {
+			if s, err = s.Append(cs[n-1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tree, err := NewTreeWithHashStrategy(cs[:n], "sha256d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s.MerkleRoot(), tree.MerkleRoot) {
+			t.Fatalf("[size:%d] error: expected hash equal to %v got %v", n, tree.MerkleRoot, s.MerkleRoot())
+		}
+		for i := 0; i < n; i++ {
+			merklePath, index, err := s.GetMerklePath(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			leafHash, _ := cs[i].CalculateHash()
+			ok, err := VerifyMerklePath(leafHash, s.MerkleRoot(), merklePath, index, "sha256d")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[size:%d leaf:%d] error: expected valid merkle path", n, i)
+			}
+		}
+	}
+}
+
+func TestSnapshot_Immutable(t *testing.T) {
+	cs := testContents(10, "leaf")
+	history, err := NewSnapshotHistory(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSnapshot(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Publish(s)
+	oldRoot := append([]byte{}, s.MerkleRoot()...)
+	oldPath, oldIndex, err := s.GetMerklePath(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := s.Update(4, TestSHA256Content{x: "changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Publish(updated)
+	appended, err := updated.Append(testContents(5, "more")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Publish(appended)
+
+	if !bytes.Equal(s.MerkleRoot(), oldRoot) || s.Len() != 10 {
+		t.Errorf("error: expected original snapshot to be unchanged")
+	}
+	leafHash, _ := cs[4].CalculateHash()
+	if ok, _ := VerifyMerklePath(leafHash, oldRoot, oldPath, oldIndex, "sha256"); !ok {
+		t.Errorf("error: expected old proof to stay valid")
+	}
+	leaf, err := s.Leaf(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := leaf.Equals(cs[4]); !ok {
+		t.Errorf("error: expected original leaf in old snapshot")
+	}
+	if leaf, err = updated.Leaf(4); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := leaf.Equals(TestSHA256Content{x: "changed"}); !ok {
+		t.Errorf("error: expected changed leaf in updated snapshot")
+	}
+	for _, index := range []int{-1, s.Len()} {
+		if _, err := s.Leaf(index); err == nil {
+			t.Errorf("[index:%d] error: expected error for leaf index out of range", index)
+		}
+	}
+
+	expected := append(append([]Content{}, cs...), testContents(5, "more")...)
+	expected[4] = TestSHA256Content{x: "changed"}
+	tree, err := NewTree(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(appended.MerkleRoot(), tree.MerkleRoot) {
+		t.Errorf("error: expected hash equal to %v got %v", tree.MerkleRoot, appended.MerkleRoot())
+	}
+	if i, _ := appended.IndexOf(TestSHA256Content{x: "more2"}); i != 12 {
+		t.Errorf("error: expected index 12 got %d", i)
+	}
+
+	if history.Get(oldRoot) != s || history.Latest() != appended || len(history.Roots()) != 3 {
+		t.Errorf("error: unexpected history")
+	}
+	more, err := appended.Append(TestSHA256Content{x: "last"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Publish(more)
+	if history.Get(oldRoot) != nil {
+		t.Errorf("error: expected oldest snapshot to be dropped")
+	}
+	if _, err := s.Update(10, cs[0]); err == nil {
+		t.Errorf("error: expected error for index out of range")
+	}
+}
+
+func TestNewSnapshotHistory(t *testing.T) {
+	for _, max := range []int{-1, 0} {
+		if _, err := NewSnapshotHistory(max); err == nil {
+			t.Errorf("[max:%d] error: expected error for history without snapshots", max)
+		}
+	}
+	history, err := NewSnapshotHistory(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, prefix := range []string{"a", "b"} {
+		s, err := NewSnapshot(testContents(3, prefix))
+		if err != nil {
+			t.Fatal(err)
+		}
+		history.Publish(s)
+		if history.Latest() != s || len(history.Roots()) != 1 {
+			t.Errorf("[snapshot:%s] error: expected history to keep the latest snapshot only", prefix)
+		}
+	}
+}
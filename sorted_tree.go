@@ -0,0 +1,268 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// -----------------------------------------------------------------------
+// Sorted trees and non-membership proofs
+// -----------------------------------------------------------------------
+
+// KeyFunc returns the key by which a SortedMerkleTree orders the content @c.
+type KeyFunc func(c Content) ([]byte, error)
+
+// SortedMerkleTree is a MerkleTree whose leafs are ordered by the keys returned by its
+// KeyFunc, which must be unique. This allows to prove that a key is absent by presenting
+// the two adjacent leafs whose keys enclose it, see ProveNonMembership. The tree must not
+// be extended or rebuilt with other content, as that would break the order of its leafs.
+type SortedMerkleTree struct {
+	Tree *MerkleTree
+	keys [][]byte
+}
+
+// SortedLeafProof proves a leaf of a SortedMerkleTree against the root of the tree.
+// MerklePath and Index are in the format of GetMerklePath.
+type SortedLeafProof struct {
+	Leaf       Content
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// NonMembershipProof proves that Key is not in a SortedMerkleTree by the adjacent leafs
+// Left and Right, whose keys enclose Key. Left is nil if Key is smaller than the key of
+// the first leaf, Right is nil if Key is greater than the key of the last leaf.
+type NonMembershipProof struct {
+	Key   []byte
+	Left  *SortedLeafProof
+	Right *SortedLeafProof
+}
+
+// NewSortedTree creates a new SortedMerkleTree using the content @cs ordered by @key.
+// Returns an error if two contents have the same key.
+func NewSortedTree(cs []Content, key KeyFunc) (*SortedMerkleTree, error) {
+	return NewSortedTreeWithHashStrategy(cs, key, "sha256")
+}
+
+// NewSortedTreeWithHashStrategy creates a new SortedMerkleTree using the content @cs
+// ordered by @key and the hash strategy @hashStrategy, see NewTreeWithHashStrategy.
+func NewSortedTreeWithHashStrategy(cs []Content, key KeyFunc, hashStrategy string) (*SortedMerkleTree, error) {
+	type keyedContent struct {
+		key []byte
+		c   Content
+	}
+	keyed := make([]keyedContent, len(cs))
+	for i, c := range cs {
+		k, err := key(c)
+		if err != nil {
+			return nil, err
+		}
+		keyed[i] = keyedContent{key: k, c: c}
+	}
+	sort.Slice(keyed, func(i, j int) bool {
+		return bytes.Compare(keyed[i].key, keyed[j].key) < 0
+	})
+	st := &SortedMerkleTree{}
+	sorted := make([]Content, len(keyed))
+	for i, kc := range keyed {
+		if i > 0 && bytes.Equal(kc.key, keyed[i-1].key) {
+			return nil, errors.New("error: duplicate key in sorted tree")
+		}
+		sorted[i] = kc.c
+		st.keys = append(st.keys, kc.key)
+	}
+	tree, err := NewTreeWithHashStrategy(sorted, hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	st.Tree = tree
+	return st, nil
+}
+
+// MerkleRoot returns the root hash of the tree.
+func (st *SortedMerkleTree) MerkleRoot() []byte {
+	return st.Tree.MerkleRoot
+}
+
+// Len returns the number of leafs in the tree, not counting the duplicate leaf.
+func (st *SortedMerkleTree) Len() int {
+	return len(st.keys)
+}
+
+// Find returns the position of the leaf with @key and true, or the position at which a
+// leaf with @key would be inserted and false.
+func (st *SortedMerkleTree) Find(key []byte) (int, bool) {
+	i := sort.Search(len(st.keys), func(i int) bool {
+		return bytes.Compare(st.keys[i], key) >= 0
+	})
+	return i, i < len(st.keys) && bytes.Equal(st.keys[i], key)
+}
+
+// leafProof returns the proof of the leaf at position @i.
+func (st *SortedMerkleTree) leafProof(i int) (*SortedLeafProof, error) {
+	merklePath, index, err := st.Tree.GetMerklePathAt(i)
+	if err != nil {
+		return nil, err
+	}
+	return &SortedLeafProof{
+		Leaf:       st.Tree.Leafs[i].C,
+		MerklePath: merklePath,
+		Index:      index,
+	}, nil
+}
+
+// ProveMembership returns the proof of the leaf with @key.
+func (st *SortedMerkleTree) ProveMembership(key []byte) (*SortedLeafProof, error) {
+	i, ok := st.Find(key)
+	if !ok {
+		return nil, errors.New("error: key not in tree")
+	}
+	return st.leafProof(i)
+}
+
+// ProveNonMembership returns the proof that no leaf has @key.
+func (st *SortedMerkleTree) ProveNonMembership(key []byte) (*NonMembershipProof, error) {
+	i, ok := st.Find(key)
+	if ok {
+		return nil, errors.New("error: key in tree")
+	}
+	proof := &NonMembershipProof{Key: key}
+	var err error
+	if i > 0 {
+		if proof.Left, err = st.leafProof(i - 1); err != nil {
+			return nil, err
+		}
+	}
+	if i < len(st.keys) {
+		if proof.Right, err = st.leafProof(i); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// leafPosition returns the position of a leaf among the leafs of its tree, derived from
+// the indexes of its Merkle path. An index of 0 means the node is a right child.
+func leafPosition(index []int64) uint64 {
+	var pos uint64
+	for level, i := range index {
+		if i == 0 {
+			pos |= 1 << uint(level)
+		}
+	}
+	return pos
+}
+
+// verify returns the key of the leaf of @p if it is proven against @root.
+func (p *SortedLeafProof) verify(root []byte, key KeyFunc, hashStrategy string) ([]byte, bool, error) {
+	if p.Leaf == nil {
+		return nil, false, errors.New("error: leaf proof without leaf")
+	}
+	leafHash, err := p.Leaf.CalculateHash()
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := VerifyMerklePath(leafHash, root, p.MerklePath, p.Index, hashStrategy)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	k, err := key(p.Leaf)
+	if err != nil {
+		return nil, false, err
+	}
+	return k, true, nil
+}
+
+// VerifyMembership returns true if @proof proves a leaf with @key against @root.
+func VerifyMembership(root []byte, key []byte, proof *SortedLeafProof, keyFunc KeyFunc, hashStrategy string) (bool, error) {
+	if proof == nil {
+		return false, errors.New("error: no proof")
+	}
+	k, ok, err := proof.verify(root, keyFunc, hashStrategy)
+	if err != nil || !ok {
+		return false, err
+	}
+	return bytes.Equal(k, key), nil
+}
+
+// VerifyNonMembership returns true if @proof proves that @key is not in the sorted tree
+// with @root. Both leafs must be proven against @root, enclose @key and be adjacent. A
+// single leaf must be the first leaf for a smaller key or the last leaf for a greater
+// key. The root is assumed to be that of a SortedMerkleTree with @keyFunc.
+func VerifyNonMembership(root []byte, key []byte, proof *NonMembershipProof, keyFunc KeyFunc, hashStrategy string) (bool, error) {
+	if proof == nil {
+		return false, errors.New("error: no proof")
+	}
+	if !bytes.Equal(proof.Key, key) {
+		return false, nil
+	}
+	if proof.Left == nil && proof.Right == nil {
+		return false, errors.New("error: non-membership proof without leafs")
+	}
+	if proof.Left != nil {
+		leftKey, ok, err := proof.Left.verify(root, keyFunc, hashStrategy)
+		if err != nil || !ok {
+			return false, err
+		}
+		if bytes.Compare(leftKey, key) >= 0 {
+			return false, nil
+		}
+	}
+	if proof.Right != nil {
+		rightKey, ok, err := proof.Right.verify(root, keyFunc, hashStrategy)
+		if err != nil || !ok {
+			return false, err
+		}
+		if bytes.Compare(key, rightKey) >= 0 {
+			return false, nil
+		}
+	}
+	switch {
+	case proof.Left == nil:
+		return isFirstLeaf(proof.Right), nil
+	case proof.Right == nil:
+		return isLastLeaf(proof.Left, hashStrategy)
+	default:
+		if len(proof.Left.Index) != len(proof.Right.Index) {
+			return false, nil
+		}
+		return leafPosition(proof.Right.Index) == leafPosition(proof.Left.Index)+1, nil
+	}
+}
+
+// isFirstLeaf returns true if the leaf of @p is the left child on every level.
+func isFirstLeaf(p *SortedLeafProof) bool {
+	return leafPosition(p.Index) == 0
+}
+
+// isLastLeaf returns true if the leaf of @p is the last leaf of its tree, i.e. the node
+// on its path is a right child on every level or its sibling is its duplicate. With
+// unique keys no other two nodes of a tree have equal hashes.
+func isLastLeaf(p *SortedLeafProof, hashStrategy string) (bool, error) {
+	h, ok := GetHashStrategies()[hashStrategy]
+	if !ok {
+		return false, fmt.Errorf("error: unknown hash strategy %s", hashStrategy)
+	}
+	hash, err := p.Leaf.CalculateHash()
+	if err != nil {
+		return false, err
+	}
+	for level, sibling := range p.MerklePath {
+		if p.Index[level] == 1 {
+			if !bytes.Equal(sibling, hash) {
+				return false, nil
+			}
+			hash = concatHashes(hash, sibling)
+		} else {
+			hash = concatHashes(sibling, hash)
+		}
+		h.Reset()
+		if _, err := h.Write(hash); err != nil {
+			return false, err
+		}
+		hash = h.Sum(nil)
+	}
+	return true, nil
+}
@@ -0,0 +1,146 @@
+package merkletree
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testTradeKey keys a TestSHA256Content by its value.
+func testTradeKey(c Content) ([]byte, error) {
+	tc, ok := c.(TestSHA256Content)
+	if !ok {
+		return nil, errors.New("error: unexpected content")
+	}
+	return []byte(tc.x), nil
+}
+
+// sortedTradeTree returns a sorted tree of @n trades with even ids, inserted in reverse order.
+func sortedTradeTree(t *testing.T, n int) *SortedMerkleTree {
+	var cs []Content
+	for i := n - 1; i >= 0; i-- {
+		cs = append(cs, TestSHA256Content{x: fmt.Sprintf("trade-%03d", 2*i)})
+	}
+	st, err := NewSortedTree(cs, testTradeKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestSortedMerkleTree_Order(t *testing.T) {
+	st := sortedTradeTree(t, 5)
+	for i := 0; i < st.Len(); i++ {
+		key, _ := testTradeKey(st.Tree.Leafs[i].C)
+		if want := fmt.Sprintf("trade-%03d", 2*i); string(key) != want {
+			t.Errorf("[case:%d] error: expected leaf %s got %s", i, want, key)
+		}
+	}
+	cs := []Content{TestSHA256Content{x: "a"}, TestSHA256Content{x: "a"}}
+	if _, err := NewSortedTree(cs, testTradeKey); err == nil {
+		t.Errorf("error: expected error for duplicate keys")
+	}
+}
+
+func TestSortedMerkleTree_ProveMembership(t *testing.T) {
+	st := sortedTradeTree(t, 7)
+	for i := 0; i < st.Len(); i++ {
+		key := []byte(fmt.Sprintf("trade-%03d", 2*i))
+		proof, err := st.ProveMembership(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := VerifyMembership(st.MerkleRoot(), key, proof, testTradeKey, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("[case:%d] error: expected valid membership proof", i)
+		}
+		if _, err := st.ProveNonMembership(key); err == nil {
+			t.Errorf("[case:%d] error: expected error for non-membership of present key", i)
+		}
+	}
+	if _, err := st.ProveMembership([]byte("trade-001")); err == nil {
+		t.Errorf("error: expected error for membership of absent key")
+	}
+}
+
+func TestSortedMerkleTree_ProveNonMembership(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		st := sortedTradeTree(t, n)
+		// Odd ids are absent, including one before the first and one after the last leaf.
+		absent := []string{"trade", "trade-999"}
+		for i := 0; i < n; i++ {
+			absent = append(absent, fmt.Sprintf("trade-%03d", 2*i+1))
+		}
+		for _, key := range absent {
+			proof, err := st.ProveNonMembership([]byte(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := VerifyNonMembership(st.MerkleRoot(), []byte(key), proof, testTradeKey, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[n:%d key:%s] error: expected valid non-membership proof", n, key)
+			}
+		}
+	}
+}
+
+func TestSortedMerkleTree_InvalidNonMembership(t *testing.T) {
+	st := sortedTradeTree(t, 6)
+	root := st.MerkleRoot()
+	leaf := func(key string) *SortedLeafProof {
+		proof, err := st.ProveMembership([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proof
+	}
+	for i, test := range []struct {
+		key   string
+		proof *NonMembershipProof
+	}{
+		// Leafs that enclose the key but are not adjacent hide trade-004.
+		{"trade-003", &NonMembershipProof{Left: leaf("trade-002"), Right: leaf("trade-006")}},
+		// Leafs that do not enclose the key.
+		{"trade-005", &NonMembershipProof{Left: leaf("trade-000"), Right: leaf("trade-002")}},
+		// A single leaf that is not the first leaf.
+		{"trade-001", &NonMembershipProof{Right: leaf("trade-002")}},
+		// A single leaf that is not the last leaf.
+		{"trade-999", &NonMembershipProof{Left: leaf("trade-008")}},
+	} {
+		test.proof.Key = []byte(test.key)
+		ok, err := VerifyNonMembership(root, []byte(test.key), test.proof, testTradeKey, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("[case:%d] error: expected invalid non-membership proof", i)
+		}
+	}
+
+	proof, err := st.ProveNonMembership([]byte("trade-003"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := VerifyNonMembership(root, []byte("trade-005"), proof, testTradeKey, "sha256"); ok {
+		t.Errorf("error: expected proof of another key to be invalid")
+	}
+	other := sortedTradeTree(t, 7)
+	if ok, _ := VerifyNonMembership(other.MerkleRoot(), []byte("trade-003"), proof, testTradeKey, "sha256"); ok {
+		t.Errorf("error: expected proof to be invalid against another root")
+	}
+	if _, err := VerifyNonMembership(root, []byte("x"), &NonMembershipProof{Key: []byte("x")}, testTradeKey, "sha256"); err == nil {
+		t.Errorf("error: expected error for proof without leafs")
+	}
+	if _, err := VerifyNonMembership(root, []byte("x"), nil, testTradeKey, "sha256"); err == nil {
+		t.Errorf("error: expected error for nil non-membership proof")
+	}
+	if _, err := VerifyMembership(root, []byte("x"), nil, testTradeKey, "sha256"); err == nil {
+		t.Errorf("error: expected error for nil membership proof")
+	}
+}
@@ -0,0 +1,373 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+)
+
+// -----------------------------------------------------------------------
+// Tiled transparency log
+// -----------------------------------------------------------------------
+
+// TileHeight is the height of the hash tiles written by TileLog, as used by the Go
+// checksum database.
+const TileHeight = 8
+
+// checkpointFile is the name of the file holding the signed tree head of a TileLog.
+const checkpointFile = "latest"
+
+// LeafRecord returns the record text for the leaf hash @leafHash: its base64 encoding
+// followed by a newline.
+func LeafRecord(leafHash []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(leafHash) + "\n")
+}
+
+// TileLog writes a transparency log in the tiled layout of golang.org/x/mod/sumdb/tlog
+// to a directory: hash tiles of height 8 under tile/8/L/NNN, data tiles holding the
+// records under tile/8/data/NNN, and a signed note with the tree head in the file latest.
+// Records must be valid record text, see tlog.FormatRecord.
+type TileLog struct {
+	dir    string
+	signer note.Signer
+	size   int64
+}
+
+// OpenTileLog opens the tile log in the directory @dir, creating it if it does not exist.
+// Checkpoints are signed with @signer, an existing checkpoint is verified with @verifier.
+func OpenTileLog(dir string, signer note.Signer, verifier note.Verifier) (*TileLog, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	l := &TileLog{dir: dir, signer: signer}
+	msg, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree, err := openCheckpoint(msg, note.VerifierList(verifier))
+	if err != nil {
+		return nil, err
+	}
+	l.size = tree.N
+	return l, nil
+}
+
+// Size returns the number of records in the log.
+func (l *TileLog) Size() int64 {
+	return l.size
+}
+
+// ReadHashes reads the stored hashes with the indexes @indexes from the tiles on disk
+// in order to implement tlog.HashReader. The tiles are trusted as they were written by the log.
+func (l *TileLog) ReadHashes(indexes []int64) ([]tlog.Hash, error) {
+	hashes := make([]tlog.Hash, len(indexes))
+	for i, index := range indexes {
+		t := tlog.TileForIndex(TileHeight, index)
+		data, err := readTileFile(l.dir, t)
+		if err != nil {
+			return nil, err
+		}
+		if hashes[i], err = tlog.HashFromTile(t, data, index); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// Append appends @records to the log, writes the new tiles and signs a new checkpoint.
+// Returns the new tree head.
+func (l *TileLog) Append(records [][]byte) (tlog.Tree, error) {
+	oldSize := l.size
+	newSize := oldSize + int64(len(records))
+	var pending []tlog.Hash
+	// pendingReader serves hashes computed in this call before they are written to tiles.
+	pendingReader := tlog.HashReaderFunc(func(indexes []int64) ([]tlog.Hash, error) {
+		base := tlog.StoredHashIndex(0, oldSize)
+		hashes := make([]tlog.Hash, len(indexes))
+		for i, index := range indexes {
+			if index >= base {
+				hashes[i] = pending[index-base]
+				continue
+			}
+			h, err := l.ReadHashes([]int64{index})
+			if err != nil {
+				return nil, err
+			}
+			hashes[i] = h[0]
+		}
+		return hashes, nil
+	})
+	for i, record := range records {
+		if _, err := tlog.FormatRecord(oldSize+int64(i), record); err != nil {
+			return tlog.Tree{}, fmt.Errorf("error: record %d: %v", oldSize+int64(i), err)
+		}
+		hashes, err := tlog.StoredHashes(oldSize+int64(i), record, pendingReader)
+		if err != nil {
+			return tlog.Tree{}, err
+		}
+		pending = append(pending, hashes...)
+	}
+
+	for _, t := range tlog.NewTiles(TileHeight, oldSize, newSize) {
+		data, err := tlog.ReadTileData(t, pendingReader)
+		if err != nil {
+			return tlog.Tree{}, err
+		}
+		if err := writeTileFile(l.dir, t, data); err != nil {
+			return tlog.Tree{}, err
+		}
+		if t.L == 0 {
+			if err := l.writeDataTile(t, records, oldSize); err != nil {
+				return tlog.Tree{}, err
+			}
+		}
+	}
+
+	hash, err := tlog.TreeHash(newSize, pendingReader)
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+	tree := tlog.Tree{N: newSize, Hash: hash}
+	msg, err := note.Sign(&note.Note{Text: string(tlog.FormatTree(tree))}, l.signer)
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(l.dir, checkpointFile), msg); err != nil {
+		return tlog.Tree{}, err
+	}
+	l.size = newSize
+	return tree, nil
+}
+
+// AppendTree appends the leaf hashes of @m as records to the log, see LeafRecord.
+// The duplicate of the last leaf is not appended.
+func (l *TileLog) AppendTree(m *MerkleTree) (tlog.Tree, error) {
+	var records [][]byte
+	for _, leaf := range m.Leafs {
+		if !leaf.Dup {
+			records = append(records, LeafRecord(leaf.Hash))
+		}
+	}
+	return l.Append(records)
+}
+
+// writeDataTile writes the data tile corresponding to the level 0 hash tile @t. Records
+// before @oldSize are read back from the previous data tile.
+func (l *TileLog) writeDataTile(t tlog.Tile, records [][]byte, oldSize int64) error {
+	start := t.N << uint(t.H)
+	var data []byte
+	for i := start; i < start+int64(t.W); i++ {
+		var record []byte
+		if i >= oldSize {
+			record = records[i-oldSize]
+		} else {
+			var err error
+			if record, err = readRecord(l.dir, i, oldSize); err != nil {
+				return err
+			}
+		}
+		msg, err := tlog.FormatRecord(i, record)
+		if err != nil {
+			return err
+		}
+		data = append(data, msg...)
+	}
+	t.L = -1
+	return writeTileFile(l.dir, t, data)
+}
+
+// TileLogReader reads a tile log from a directory and reconstructs inclusion and
+// consistency proofs purely from the tiles on disk.
+type TileLogReader struct {
+	dir       string
+	verifiers note.Verifiers
+}
+
+// NewTileLogReader returns a reader for the tile log in the directory @dir whose
+// checkpoints are verified with @verifiers.
+func NewTileLogReader(dir string, verifiers note.Verifiers) *TileLogReader {
+	return &TileLogReader{dir: dir, verifiers: verifiers}
+}
+
+// Checkpoint returns the tree head of the log's signed checkpoint.
+func (r *TileLogReader) Checkpoint() (tlog.Tree, error) {
+	msg, err := os.ReadFile(filepath.Join(r.dir, checkpointFile))
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+	return openCheckpoint(msg, r.verifiers)
+}
+
+// Height returns the height of the tiles in order to implement tlog.TileReader.
+func (r *TileLogReader) Height() int {
+	return TileHeight
+}
+
+// ReadTiles reads the tiles @tiles from disk in order to implement tlog.TileReader.
+func (r *TileLogReader) ReadTiles(tiles []tlog.Tile) ([][]byte, error) {
+	data := make([][]byte, len(tiles))
+	for i, t := range tiles {
+		var err error
+		if data[i], err = readTileFile(r.dir, t); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// SaveTiles does nothing as the tiles are already on disk, in order to implement tlog.TileReader.
+func (r *TileLogReader) SaveTiles(tiles []tlog.Tile, data [][]byte) {}
+
+// ProveRecord returns the inclusion proof of the record with index @index in @tree.
+// The proof can be checked with tlog.CheckRecord.
+func (r *TileLogReader) ProveRecord(tree tlog.Tree, index int64) (tlog.RecordProof, error) {
+	return tlog.ProveRecord(tree.N, index, tlog.TileHashReader(tree, r))
+}
+
+// ProveTree returns the consistency proof between the tree of size @oldSize and @tree.
+// The proof can be checked with tlog.CheckTree.
+func (r *TileLogReader) ProveTree(tree tlog.Tree, oldSize int64) (tlog.TreeProof, error) {
+	return tlog.ProveTree(tree.N, oldSize, tlog.TileHashReader(tree, r))
+}
+
+// Record returns the record with index @index in @tree and verifies it against the tree.
+func (r *TileLogReader) Record(tree tlog.Tree, index int64) ([]byte, error) {
+	if index < 0 || index >= tree.N {
+		return nil, errors.New("error: record index out of range")
+	}
+	record, err := readRecord(r.dir, index, tree.N)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := r.ProveRecord(tree, index)
+	if err != nil {
+		return nil, err
+	}
+	if err := tlog.CheckRecord(proof, tree.N, tree.Hash, index, tlog.RecordHash(record)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// LookupRecord returns the index of the record @record in @tree, or -1 if it is not in the log.
+func (r *TileLogReader) LookupRecord(tree tlog.Tree, record []byte) (int64, error) {
+	for index := int64(0); index < tree.N; index += 1 << TileHeight {
+		data, err := readTileFile(r.dir, dataTile(index, tree.N))
+		if err != nil {
+			return -1, err
+		}
+		for len(data) > 0 {
+			id, text, rest, err := tlog.ParseRecord(data)
+			if err != nil {
+				return -1, err
+			}
+			if bytes.Equal(text, record) {
+				if _, err := r.Record(tree, id); err != nil {
+					return -1, err
+				}
+				return id, nil
+			}
+			data = rest
+		}
+	}
+	return -1, nil
+}
+
+// openCheckpoint verifies the signed note @msg and parses the tree head it holds.
+func openCheckpoint(msg []byte, verifiers note.Verifiers) (tlog.Tree, error) {
+	n, err := note.Open(msg, verifiers)
+	if err != nil {
+		return tlog.Tree{}, err
+	}
+	return tlog.ParseTree([]byte(n.Text))
+}
+
+// dataTile returns the data tile holding the record with index @index in a log of size @size.
+func dataTile(index, size int64) tlog.Tile {
+	t := tlog.Tile{H: TileHeight, L: -1, N: index >> TileHeight, W: 1 << TileHeight}
+	if end := size - t.N<<TileHeight; end < int64(t.W) {
+		t.W = int(end)
+	}
+	return t
+}
+
+// readRecord reads the record with index @index from the data tiles of a log of size @size.
+func readRecord(dir string, index, size int64) ([]byte, error) {
+	data, err := readTileFile(dir, dataTile(index, size))
+	if err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		id, text, rest, err := tlog.ParseRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		if id == index {
+			return text, nil
+		}
+		data = rest
+	}
+	return nil, fmt.Errorf("error: record %d not in data tile", index)
+}
+
+// readTileFile reads the tile @t from @dir. If the tile is not on disk with its exact
+// width, it is cut from the next wider tile of the same position.
+func readTileFile(dir string, t tlog.Tile) ([]byte, error) {
+	for w := t.W; w <= 1<<uint(t.H); w++ {
+		wide := t
+		wide.W = w
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(wide.Path())))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t.L >= 0 {
+			if len(data) < t.W*tlog.HashSize {
+				return nil, fmt.Errorf("error: tile %s truncated", wide.Path())
+			}
+			return data[:t.W*tlog.HashSize], nil
+		}
+		// Data tiles are cut after the first t.W records.
+		rest := data
+		for i := 0; i < t.W; i++ {
+			if _, _, rest, err = tlog.ParseRecord(rest); err != nil {
+				return nil, err
+			}
+		}
+		return data[:len(data)-len(rest)], nil
+	}
+	return nil, fmt.Errorf("error: tile %s not found", t.Path())
+}
+
+// writeTileFile writes the tile @t with content @data to @dir.
+func writeTileFile(dir string, t tlog.Tile, data []byte) error {
+	path := filepath.Join(dir, filepath.FromSlash(t.Path()))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes @data to a temporary file, syncs it and renames it to @path, such
+// that @path holds either its old or its new content after a crash.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
@@ -0,0 +1,192 @@
+package merkletree
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+)
+
+func newTestKeys(t *testing.T) (note.Signer, note.Verifier) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "merkletree.test/log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := note.NewSigner(skey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := note.NewVerifier(vkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, verifier
+}
+
+func testRecords(from, to int) [][]byte {
+	var records [][]byte
+	for i := from; i < to; i++ {
+		records = append(records, []byte(fmt.Sprintf("record %d\n", i)))
+	}
+	return records
+}
+
+func TestTileLog_Proofs(t *testing.T) {
+	dir := t.TempDir()
+	signer, verifier := newTestKeys(t)
+
+	// in-memory log computed by tlog for comparison
+	var stored []tlog.Hash
+	memory := tlog.HashReaderFunc(func(indexes []int64) ([]tlog.Hash, error) {
+		hashes := make([]tlog.Hash, len(indexes))
+		for i, index := range indexes {
+			hashes[i] = stored[index]
+		}
+		return hashes, nil
+	})
+
+	var trees []tlog.Tree
+	sizes := []int{1, 2, 5, 256, 300, 600}
+	for i := range sizes {
+		// reopen the log for each batch
+		l, err := OpenTileLog(dir, signer, verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from := 0
+		if i > 0 {
+			from = sizes[i-1]
+		}
+		if l.Size() != int64(from) {
+			t.Fatalf("error: expected log size %d got %d", from, l.Size())
+		}
+		records := testRecords(from, sizes[i])
+		tree, err := l.Append(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, record := range records {
+			hashes, err := tlog.StoredHashes(int64(from+j), record, memory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored = append(stored, hashes...)
+		}
+		expected, err := tlog.TreeHash(int64(sizes[i]), memory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tree.N != int64(sizes[i]) || tree.Hash != expected {
+			t.Errorf("[size:%d] error: expected tree hash %v got %v", sizes[i], expected, tree.Hash)
+		}
+		trees = append(trees, tree)
+	}
+
+	r := NewTileLogReader(dir, note.VerifierList(verifier))
+	latest, err := r.Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != trees[len(trees)-1] {
+		t.Errorf("error: expected checkpoint %v got %v", trees[len(trees)-1], latest)
+	}
+	for _, tree := range trees {
+		for _, index := range []int64{0, tree.N / 2, tree.N - 1} {
+			proof, err := r.ProveRecord(tree, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record, err := r.Record(tree, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(record) != fmt.Sprintf("record %d\n", index) {
+				t.Errorf("[size:%d] error: unexpected record %q", tree.N, record)
+			}
+			if err := tlog.CheckRecord(proof, tree.N, tree.Hash, index, tlog.RecordHash(record)); err != nil {
+				t.Errorf("[size:%d index:%d] error: invalid record proof: %v", tree.N, index, err)
+			}
+		}
+		proof, err := r.ProveTree(latest, tree.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tlog.CheckTree(proof, latest.N, latest.Hash, tree.N, tree.Hash); err != nil {
+			t.Errorf("[size:%d] error: invalid tree proof: %v", tree.N, err)
+		}
+	}
+
+	index, err := r.LookupRecord(latest, []byte("record 299\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 299 {
+		t.Errorf("error: expected record index 299 got %d", index)
+	}
+	if index, _ := r.LookupRecord(latest, []byte("record 600\n")); index != -1 {
+		t.Errorf("error: expected missing record got index %d", index)
+	}
+}
+
+func TestTileLog_AppendTree(t *testing.T) {
+	dir := t.TempDir()
+	signer, verifier := newTestKeys(t)
+	l, err := OpenTileLog(dir, signer, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := makeStorageTree(t, "trades", "a", "b", "c")
+	head, err := l.AppendTree(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.N != 3 {
+		t.Errorf("error: expected 3 records got %d", head.N)
+	}
+	r := NewTileLogReader(dir, note.VerifierList(verifier))
+	index, err := r.LookupRecord(head, LeafRecord(tree.Leafs[1].Hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 1 {
+		t.Errorf("error: expected leaf index 1 got %d", index)
+	}
+	if _, err := l.Append([][]byte{[]byte("not a record")}); err == nil {
+		t.Errorf("error: expected error for invalid record text")
+	}
+}
+
+func TestTileLog_Tampered(t *testing.T) {
+	dir := t.TempDir()
+	signer, verifier := newTestKeys(t)
+	l, err := OpenTileLog(dir, signer, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := l.Append(testRecords(0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(tlog.Tile{H: TileHeight, L: -1, N: 0, W: 10}.Path()))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-3] = 'X'
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewTileLogReader(dir, note.VerifierList(verifier))
+	if _, err := r.Record(tree, 9); err == nil {
+		t.Errorf("error: expected error for tampered record")
+	}
+
+	_, otherVerifier := newTestKeys(t)
+	if _, err := NewTileLogReader(dir, note.VerifierList(otherVerifier)).Checkpoint(); err == nil {
+		t.Errorf("error: expected error for checkpoint signed by unknown key")
+	}
+}
@@ -0,0 +1,200 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+)
+
+// TimeSpan is the closed time interval [From, To].
+type TimeSpan struct {
+	From time.Time
+	To   time.Time
+}
+
+// Overlaps returns true if the time spans @ts and @other have at least one instant in common.
+func (ts TimeSpan) Overlaps(other TimeSpan) bool {
+	return !ts.To.Before(other.From) && !other.To.Before(ts.From)
+}
+
+// Contains returns true if @t lies within the time span @ts.
+func (ts TimeSpan) Contains(t time.Time) bool {
+	return !t.Before(ts.From) && !t.After(ts.To)
+}
+
+// BucketProof is the proof of a StorageBucket from its leaf up to the root of a forest.
+type BucketProof struct {
+	Bucket     StorageBucket
+	MerklePath [][]byte
+	Index      []int64
+}
+
+// TimeForest is a Forest of trees made from StorageBuckets. Its member trees are ordered
+// and indexed by the Timestamps of their buckets, such that data can be looked up by time.
+type TimeForest struct {
+	Forest
+	spans []TimeSpan
+}
+
+// NewTimeForest creates a new TimeForest from the trees @trees of StorageBuckets.
+func NewTimeForest(trees []*MerkleTree) (*TimeForest, error) {
+	tf := &TimeForest{}
+	for _, tree := range trees {
+		span, err := treeSpan(tree)
+		if err != nil {
+			return nil, err
+		}
+		tf.insert(tree, span)
+	}
+	if err := tf.Rebuild(); err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+// treeSpan returns the time span covered by the timestamped buckets of @tree.
+// Unused buckets of a pool carry no timestamp and are ignored.
+func treeSpan(tree *MerkleTree) (span TimeSpan, err error) {
+	if tree == nil || tree.Isempty() {
+		return span, errors.New("error: cannot index empty tree")
+	}
+	found := false
+	for _, leaf := range tree.Leafs {
+		sb, ok := asStorageBucket(leaf.C)
+		if !ok {
+			return span, errors.New("error: tree leaf is not a StorageBucket")
+		}
+		if leaf.Dup || sb.Timestamp.IsZero() {
+			continue
+		}
+		if !found || sb.Timestamp.Before(span.From) {
+			span.From = sb.Timestamp
+		}
+		if !found || sb.Timestamp.After(span.To) {
+			span.To = sb.Timestamp
+		}
+		found = true
+	}
+	if !found {
+		return span, errors.New("error: tree has no timestamped buckets")
+	}
+	return span, nil
+}
+
+// insert adds @tree to the member trees keeping them ordered by the start of their spans.
+func (tf *TimeForest) insert(tree *MerkleTree, span TimeSpan) {
+	i := sort.Search(len(tf.spans), func(i int) bool {
+		return tf.spans[i].From.After(span.From)
+	})
+	tf.Trees = append(tf.Trees, nil)
+	copy(tf.Trees[i+1:], tf.Trees[i:])
+	tf.Trees[i] = tree
+	tf.spans = append(tf.spans, TimeSpan{})
+	copy(tf.spans[i+1:], tf.spans[i:])
+	tf.spans[i] = span
+}
+
+// Add adds the tree @tree of StorageBuckets to the forest and rebuilds the forest tree.
+func (tf *TimeForest) Add(tree *MerkleTree) error {
+	span, err := treeSpan(tree)
+	if err != nil {
+		return err
+	}
+	tf.insert(tree, span)
+	return tf.Rebuild()
+}
+
+// Span returns the time span covered by the member tree with index @i.
+func (tf *TimeForest) Span(i int) TimeSpan {
+	return tf.spans[i]
+}
+
+// treesInRange returns the indexes of the member trees overlapping the range [@from, @to].
+func (tf *TimeForest) treesInRange(from, to time.Time) []int {
+	window := TimeSpan{From: from, To: to}
+	var indexes []int
+	for i, span := range tf.spans {
+		if span.From.After(to) {
+			break
+		}
+		if span.Overlaps(window) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// TreesInRange returns the member trees holding buckets in the time range [@from, @to].
+func (tf *TimeForest) TreesInRange(from, to time.Time) []*MerkleTree {
+	var trees []*MerkleTree
+	for _, i := range tf.treesInRange(from, to) {
+		trees = append(trees, tf.Trees[i])
+	}
+	return trees
+}
+
+// BucketsInRange returns the StorageBuckets with a Timestamp in the time range [@from, @to].
+func (tf *TimeForest) BucketsInRange(from, to time.Time) []StorageBucket {
+	window := TimeSpan{From: from, To: to}
+	var buckets []StorageBucket
+	for _, i := range tf.treesInRange(from, to) {
+		for _, leaf := range tf.Trees[i].Leafs {
+			sb, _ := asStorageBucket(leaf.C)
+			if !leaf.Dup && !sb.Timestamp.IsZero() && window.Contains(sb.Timestamp) {
+				buckets = append(buckets, sb)
+			}
+		}
+	}
+	return buckets
+}
+
+// DataInRange returns true if @data is in a bucket with a Timestamp in the time range
+// [@from, @to] along with the bucket.
+func (tf *TimeForest) DataInRange(data []byte, from, to time.Time) (bool, StorageBucket, error) {
+	for _, sb := range tf.BucketsInRange(from, to) {
+		content, err := sb.ReadContent()
+		if err != nil {
+			return false, StorageBucket{}, err
+		}
+		for _, item := range content {
+			if bytes.Equal(item, data) {
+				return true, sb, nil
+			}
+		}
+	}
+	return false, StorageBucket{}, nil
+}
+
+// ProofsInRange returns the forest proofs of all StorageBuckets with a Timestamp in the
+// time range [@from, @to]. Each proof can be checked with VerifyForestContent.
+func (tf *TimeForest) ProofsInRange(from, to time.Time) ([]BucketProof, error) {
+	window := TimeSpan{From: from, To: to}
+	var proofs []BucketProof
+	for _, i := range tf.treesInRange(from, to) {
+		tree := tf.Trees[i]
+		if !bytes.Equal(tf.Tree.Leafs[i].Hash, tree.MerkleRoot) {
+			return nil, errors.New("error: tree root not in forest, forest needs rebuild")
+		}
+		forestPath, forestIndex, err := tf.Tree.GetMerklePathAt(i)
+		if err != nil {
+			return nil, err
+		}
+		for j, leaf := range tree.Leafs {
+			sb, _ := asStorageBucket(leaf.C)
+			if leaf.Dup || sb.Timestamp.IsZero() || !window.Contains(sb.Timestamp) {
+				continue
+			}
+			merklePath, index, err := tree.GetMerklePathAt(j)
+			if err != nil {
+				return nil, err
+			}
+			proofs = append(proofs, BucketProof{
+				Bucket:     sb,
+				MerklePath: append(merklePath, forestPath...),
+				Index:      append(index, forestIndex...),
+			})
+		}
+	}
+	return proofs, nil
+}
@@ -0,0 +1,161 @@
+package merkletree
+
+import (
+	"testing"
+	"time"
+)
+
+func makeTimedTree(t *testing.T, start time.Time, items ...string) *MerkleTree {
+	var cs []Content
+	for i, item := range items {
+		b := NewBucket(64, "trades")
+		b.WriteContent([]byte(item))
+		b.ID = item
+		b.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		cs = append(cs, bucketToStorage(*b))
+	}
+	// unused bucket without timestamp
+	cs = append(cs, bucketToStorage(*NewBucket(64, "trades")))
+	tree, err := NewTree(cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func TestTimeForest_Ranges(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	late := makeTimedTree(t, t0.Add(time.Hour), "d", "e")
+	early := makeTimedTree(t, t0, "a", "b", "c")
+
+	tf, err := NewTimeForest([]*MerkleTree{late})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tf.Add(early); err != nil {
+		t.Fatal(err)
+	}
+	if tf.Trees[0] != early || tf.Trees[1] != late {
+		t.Errorf("error: expected trees to be ordered by time")
+	}
+	if span := tf.Span(0); !span.From.Equal(t0) || !span.To.Equal(t0.Add(2*time.Minute)) {
+		t.Errorf("error: unexpected span %v", span)
+	}
+
+	tables := []struct {
+		from, to time.Time
+		trees    int
+		buckets  int
+	}{
+		{t0.Add(-time.Hour), t0.Add(-time.Minute), 0, 0},
+		{t0, t0, 1, 1},
+		{t0, t0.Add(time.Minute), 1, 2},
+		{t0.Add(30 * time.Second), t0.Add(time.Hour), 2, 3},
+		{t0, t0.Add(2 * time.Hour), 2, 5},
+		{t0.Add(3 * time.Minute), t0.Add(59 * time.Minute), 0, 0},
+	}
+	for i, table := range tables {
+		if trees := tf.TreesInRange(table.from, table.to); len(trees) != table.trees {
+			t.Errorf("[case:%d] error: got %d trees but expected %d", i, len(trees), table.trees)
+		}
+		buckets := tf.BucketsInRange(table.from, table.to)
+		if len(buckets) != table.buckets {
+			t.Errorf("[case:%d] error: got %d buckets but expected %d", i, len(buckets), table.buckets)
+		}
+		proofs, err := tf.ProofsInRange(table.from, table.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proofs) != table.buckets {
+			t.Errorf("[case:%d] error: got %d proofs but expected %d", i, len(proofs), table.buckets)
+		}
+		for _, proof := range proofs {
+			ok, err := VerifyForestContent(proof.Bucket, tf.MerkleRoot(), proof.MerklePath, proof.Index, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("[case:%d] error: expected valid proof for bucket %s", i, proof.Bucket.ID)
+			}
+		}
+	}
+}
+
+func TestTimeForest_ProofsInRangeEqualBuckets(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBucket(64, "trades")
+	b.WriteContent([]byte("a"))
+	b.Timestamp = t0
+	sb := bucketToStorage(*b)
+	tree, err := NewTree([]Content{sb, sb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf, err := NewTimeForest([]*MerkleTree{tree})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tf.ProofsInRange(t0, t0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 2 {
+		t.Fatalf("error: got %d proofs but expected 2", len(proofs))
+	}
+	for i, proof := range proofs {
+		if pos := leafPosition(proof.Index); pos != uint64(i) {
+			t.Errorf("[case:%d] error: expected proof of leaf %d got %d", i, i, pos)
+		}
+	}
+}
+
+func TestTimeForest_DataInRange(t *testing.T) {
+	t0 := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tf, err := NewTimeForest([]*MerkleTree{
+		makeTimedTree(t, t0, "a", "b"),
+		makeTimedTree(t, t0.Add(time.Hour), "c"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []struct {
+		data     string
+		from, to time.Time
+		found    bool
+	}{
+		{"a", t0, t0.Add(time.Hour), true},
+		{"c", t0, t0.Add(time.Hour), true},
+		{"c", t0, t0.Add(59 * time.Minute), false},
+		{"b", t0.Add(time.Minute), t0.Add(time.Minute), true},
+		{"x", t0, t0.Add(time.Hour), false},
+	}
+	for i, table := range tables {
+		found, sb, err := tf.DataInRange([]byte(table.data), table.from, table.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != table.found {
+			t.Errorf("[case:%d] error: got %v but expected %v", i, found, table.found)
+		}
+		if found && sb.ID != table.data {
+			t.Errorf("[case:%d] error: got bucket %s but expected %s", i, sb.ID, table.data)
+		}
+	}
+}
+
+func TestTimeForest_Errors(t *testing.T) {
+	unused, err := MakeTree(NewBucketPool(2, 64, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTimeForest([]*MerkleTree{unused}); err == nil {
+		t.Errorf("error: expected error for tree without timestamped buckets")
+	}
+	tree, err := NewTree([]Content{ByteContent{Content: []byte{1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTimeForest([]*MerkleTree{tree}); err == nil {
+		t.Errorf("error: expected error for tree without StorageBuckets")
+	}
+}
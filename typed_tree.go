@@ -0,0 +1,230 @@
+package merkletree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Hasher calculates the hash of a value of type T.
+type Hasher[T any] func(T) ([]byte, error)
+
+// typedContent wraps a value of type T in order to implement Content. The hash is
+// calculated once when the value is added to the tree.
+type typedContent[T any] struct {
+	value T
+	hash  []byte
+}
+
+// Custom marshaler for typedContent type. The value is marshaled by encoding/json.
+func (tc typedContent[T]) MarshalJSON() ([]byte, error) {
+	var out = struct {
+		Type  string `json:"_type"`
+		Value T
+		Hash  []byte
+	}{
+		Type:  "TypedContent",
+		Value: tc.value,
+		Hash:  tc.hash,
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON is a custom unmarshaler for typedContent. Unmarshaled trees hold the
+// values as json.RawMessage, see newContent and UnmarshalTypedTree.
+func (tc *typedContent[T]) UnmarshalJSON(data []byte) error {
+	var out struct {
+		Value T
+		Hash  []byte
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	tc.value = out.Value
+	tc.hash = out.Hash
+	return nil
+}
+
+// CalculateHash for typedContent in order to implement Content.
+func (tc typedContent[T]) CalculateHash() ([]byte, error) {
+	return tc.hash, nil
+}
+
+// Equals returns true if two typedContents have the same hash, false otherwise or if
+// @other is of a different type.
+func (tc typedContent[T]) Equals(other Content) (bool, error) {
+	var o typedContent[T]
+	switch oc := other.(type) {
+	case typedContent[T]:
+		o = oc
+	case *typedContent[T]:
+		if oc == nil {
+			return false, nil
+		}
+		o = *oc
+	default:
+		return false, nil
+	}
+	return bytes.Equal(tc.hash, o.hash), nil
+}
+
+// TypedTree is a Merkle tree holding values of type T. In contrast to MerkleTree the
+// values do not have to implement Content, they are hashed by the Hasher of the tree
+// and two values are considered equal if their hashes are equal.
+type TypedTree[T any] struct {
+	tree   *MerkleTree
+	hasher Hasher[T]
+}
+
+// NewTypedTree creates a new TypedTree from the values @values hashed by @hasher.
+func NewTypedTree[T any](values []T, hasher Hasher[T]) (*TypedTree[T], error) {
+	return NewTypedTreeWithHashStrategy(values, hasher, "sha256")
+}
+
+// NewTypedTreeWithHashStrategy creates a new TypedTree from the values @values hashed by
+// @hasher using the hash strategy @hashStrategy for the intermediate nodes.
+func NewTypedTreeWithHashStrategy[T any](values []T, hasher Hasher[T], hashStrategy string) (*TypedTree[T], error) {
+	if hasher == nil {
+		return nil, errors.New("error: cannot construct tree without hasher")
+	}
+	t := &TypedTree[T]{hasher: hasher}
+	cs, err := t.contents(values)
+	if err != nil {
+		return nil, err
+	}
+	t.tree, err = NewTreeWithHashStrategy(cs, hashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UnmarshalTypedTree restores a TypedTree from the JSON encoding @data of its MerkleTree,
+// see TypedTree.MarshalJSON. The values are unmarshaled into T and hashed by @hasher, and
+// the rebuilt tree must have the marshaled root.
+func UnmarshalTypedTree[T any](data []byte, hasher Hasher[T]) (*TypedTree[T], error) {
+	var m MerkleTree
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	var values []T
+	for _, leaf := range m.Leafs {
+		if leaf.Dup {
+			continue
+		}
+		tc, ok := leaf.C.(*typedContent[json.RawMessage])
+		if !ok {
+			return nil, errors.New("error: tree leaf is not a TypedContent")
+		}
+		var v T
+		if err := json.Unmarshal(tc.value, &v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	t, err := NewTypedTreeWithHashStrategy(values, hasher, m.HashStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(t.MerkleRoot(), m.MerkleRoot) {
+		return nil, errors.New("error: unmarshaled values do not match the tree root")
+	}
+	return t, nil
+}
+
+// MarshalJSON marshals the underlying MerkleTree, whose leafs hold the values.
+func (t *TypedTree[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.tree)
+}
+
+// contents wraps @values into Contents.
+func (t *TypedTree[T]) contents(values []T) ([]Content, error) {
+	var cs []Content
+	for _, v := range values {
+		c, err := t.content(v)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// content wraps the value @v into a Content.
+func (t *TypedTree[T]) content(v T) (Content, error) {
+	hash, err := t.hasher(v)
+	if err != nil {
+		return nil, err
+	}
+	return typedContent[T]{value: v, hash: hash}, nil
+}
+
+// Tree returns the underlying MerkleTree.
+func (t *TypedTree[T]) Tree() *MerkleTree {
+	return t.tree
+}
+
+// MerkleRoot returns the root hash of the tree.
+func (t *TypedTree[T]) MerkleRoot() []byte {
+	return t.tree.MerkleRoot
+}
+
+// Len returns the number of values in the tree. The duplicate of the last leaf is not counted.
+func (t *TypedTree[T]) Len() int {
+	return t.tree.LeafCount()
+}
+
+// Leaf returns the value of the leaf with index @i.
+func (t *TypedTree[T]) Leaf(i int) T {
+	return t.tree.Leafs[i].C.(typedContent[T]).value
+}
+
+// Values returns the values of the tree in the order of the leafs.
+func (t *TypedTree[T]) Values() []T {
+	values := make([]T, 0, t.Len())
+	for i := 0; i < t.Len(); i++ {
+		values = append(values, t.Leaf(i))
+	}
+	return values
+}
+
+// GetMerklePath gets Merkle path and indexes (left leaf or right leaf) of the value @v.
+func (t *TypedTree[T]) GetMerklePath(v T) ([][]byte, []int64, error) {
+	c, err := t.content(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.tree.GetMerklePath(c)
+}
+
+// VerifyContent indicates whether the value @v is in the tree and the hashes are valid for it.
+func (t *TypedTree[T]) VerifyContent(v T) (bool, error) {
+	c, err := t.content(v)
+	if err != nil {
+		return false, err
+	}
+	return t.tree.VerifyContent(c)
+}
+
+// VerifyTree validates the hashes at each level of the tree, see MerkleTree.VerifyTree.
+func (t *TypedTree[T]) VerifyTree() (bool, error) {
+	return t.tree.VerifyTree()
+}
+
+// Extend extends the tree by the values @values.
+func (t *TypedTree[T]) Extend(values []T) error {
+	cs, err := t.contents(values)
+	if err != nil {
+		return err
+	}
+	return t.tree.ExtendTree(cs)
+}
+
+// RebuildWith replaces the values of the tree and does a complete rebuild.
+func (t *TypedTree[T]) RebuildWith(values []T) error {
+	cs, err := t.contents(values)
+	if err != nil {
+		return err
+	}
+	return t.tree.RebuildTreeWith(cs)
+}
@@ -0,0 +1,181 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+)
+
+func sha256String(s string) ([]byte, error) {
+	h := sha256.New()
+	if _, err := h.Write([]byte(s)); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func TestTypedTree_MerkleRoot(t *testing.T) {
+	for i := 0; i < len(table); i++ {
+		var values []string
+		for _, c := range table[i].contents {
+			values = append(values, c.(TestSHA256Content).x)
+		}
+		tree, err := NewTypedTree(values, sha256String)
+		if err != nil {
+			t.Fatalf("[case:%d] error: unexpected error: %v", table[i].testCaseId, err)
+		}
+		if !bytes.Equal(tree.MerkleRoot(), table[i].expectedHash) {
+			t.Errorf("[case:%d] error: expected hash equal to %v got %v", table[i].testCaseId, table[i].expectedHash, tree.MerkleRoot())
+		}
+		if tree.Len() != len(values) {
+			t.Errorf("[case:%d] error: expected %d values got %d", table[i].testCaseId, len(values), tree.Len())
+		}
+		for j, v := range tree.Values() {
+			if v != values[j] {
+				t.Errorf("[case:%d] error: expected leaf %s got %s", table[i].testCaseId, values[j], v)
+			}
+		}
+	}
+}
+
+func TestTypedTree_VerifyContent(t *testing.T) {
+	type trade struct {
+		Pair  string
+		Price string
+	}
+	hasher := func(tr trade) ([]byte, error) {
+		return sha256String(tr.Pair + "/" + tr.Price)
+	}
+	trades := []trade{{"BTC-USD", "9000"}, {"ETH-USD", "200"}, {"EUR-USD", "1.1"}}
+	tree, err := NewTypedTree(trades, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range trades {
+		ok, err := tree.VerifyContent(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("error: expected %v to be in tree", tr)
+		}
+		merklePath, index, err := tree.GetMerklePath(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leafHash, _ := hasher(tr)
+		ok, err = VerifyMerklePath(leafHash, tree.MerkleRoot(), merklePath, index, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("error: expected valid merkle path for %v", tr)
+		}
+	}
+	missing := trade{"BTC-USD", "1"}
+	if ok, _ := tree.VerifyContent(missing); ok {
+		t.Errorf("error: expected %v not to be in tree", missing)
+	}
+	if err := tree.Extend([]trade{missing}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := tree.VerifyContent(missing); !ok {
+		t.Errorf("error: expected %v to be in extended tree", missing)
+	}
+	if tree.Leaf(3) != missing {
+		t.Errorf("error: expected leaf %v got %v", missing, tree.Leaf(3))
+	}
+}
+
+func TestTypedTree_JSON(t *testing.T) {
+	values := []string{"alpha", "beta", "gamma"}
+	tree, err := NewTypedTree(values, sha256String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := UnmarshalTypedTree(data, sha256String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored.MerkleRoot(), tree.MerkleRoot()) {
+		t.Errorf("error: expected root %v got %v", tree.MerkleRoot(), restored.MerkleRoot())
+	}
+	for i, v := range restored.Values() {
+		if v != values[i] {
+			t.Errorf("[case:%d] error: expected value %s got %s", i, values[i], v)
+		}
+	}
+
+	// A plain MerkleTree can be unmarshaled as well, its leafs hold the raw values.
+	var m MerkleTree
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	for i, leaf := range m.Leafs {
+		hash, err := leaf.C.CalculateHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(hash, tree.Tree().Leafs[i].Hash) {
+			t.Errorf("[case:%d] error: expected unmarshaled leaf hash %v got %v", i, tree.Tree().Leafs[i].Hash, hash)
+		}
+	}
+
+	if _, err := UnmarshalTypedTree(data, func(s string) ([]byte, error) {
+		return sha256String(s + "salt")
+	}); err == nil {
+		t.Errorf("error: expected error for hasher that does not match the root")
+	}
+}
+
+func TestContent_EqualsMixedTypes(t *testing.T) {
+	contents := []Content{
+		ByteContent{Content: []byte("a")},
+		StorageBucket{Content: []byte("a")},
+		*NewBucket(8, "a"),
+		typedContent[string]{value: "a"},
+	}
+	for i, c := range contents {
+		for j, other := range contents {
+			if i == j {
+				continue
+			}
+			ok, err := c.Equals(other)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				t.Errorf("error: expected %T not to equal %T", c, other)
+			}
+		}
+	}
+}
+
+func TestContent_EqualsNilPointer(t *testing.T) {
+	tables := []struct {
+		c     Content
+		other Content
+	}{
+		{ByteContent{Content: []byte("a")}, (*ByteContent)(nil)},
+		{FileContent{Length: 1}, (*FileContent)(nil)},
+		{PathContent{Path: "a"}, (*PathContent)(nil)},
+		{RecordContent{}, (*RecordContent)(nil)},
+		{SaltedContent{Content: ByteContent{}}, (*SaltedContent)(nil)},
+		{StorageBucket{}, (*StorageBucket)(nil)},
+		{typedContent[string]{value: "a"}, (*typedContent[string])(nil)},
+	}
+	for i, table := range tables {
+		ok, err := table.c.Equals(table.other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Errorf("[case:%d] error: expected %T not to equal a nil pointer", i, table.c)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package merkletree
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// -----------------------------------------------------------------------
+// Write-ahead log of a pool
+// -----------------------------------------------------------------------
+
+// The write-ahead log of a pool is a sequence of records:
+//
+//	record: type (1 byte) | length of data (4 bytes) | data | CRC-32 of type, length and data (4 bytes)
+//
+// with all integers big endian. A write record holds an item written to the pool, a seal
+// record holds the root of the tree the pool was sealed into. Every record is synced to
+// disk before it is acknowledged. The log is read up to the first incomplete or corrupt
+// record, which is the tail of a write interrupted by a crash and is discarded.
+
+const (
+	walWrite byte = 'W'
+	walSeal  byte = 'S'
+	// walRecordOverhead is the length of a record without its data.
+	walRecordOverhead = 9
+)
+
+// PoolWAL is the write-ahead log of a pool. It records the items written to the pool and
+// the seals of the pool, such that the pool can be rebuilt after a crash, see
+// RecoverBucketWriter. It is safe for concurrent use.
+type PoolWAL struct {
+	mu       sync.Mutex
+	path     string
+	f        *os.File
+	pending  [][]byte
+	lastSeal []byte
+}
+
+// OpenPoolWAL opens the write-ahead log at @path, creating it if it does not exist. The
+// items written since the last seal are available by Pending.
+func OpenPoolWAL(path string) (*PoolWAL, error) {
+	l := &PoolWAL{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	end := 0
+	for {
+		typ, record, n := readWALRecord(data[end:])
+		if n == 0 {
+			break
+		}
+		switch typ {
+		case walWrite:
+			l.pending = append(l.pending, record)
+		case walSeal:
+			l.pending = nil
+			l.lastSeal = record
+		}
+		end += n
+	}
+	if l.f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return nil, err
+	}
+	// Discard the tail of an interrupted write.
+	if err := l.f.Truncate(int64(end)); err != nil {
+		l.f.Close()
+		return nil, err
+	}
+	if _, err := l.f.Seek(int64(end), io.SeekStart); err != nil {
+		l.f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// readWALRecord reads the record at the start of @b and returns its type, its data and
+// its length, or a length of 0 if there is no complete and valid record.
+func readWALRecord(b []byte) (byte, []byte, int) {
+	if len(b) < walRecordOverhead {
+		return 0, nil, 0
+	}
+	length := binary.BigEndian.Uint32(b[1:5])
+	if uint64(length) > uint64(len(b)-walRecordOverhead) {
+		return 0, nil, 0
+	}
+	n := walRecordOverhead + int(length)
+	if crc32.ChecksumIEEE(b[:n-4]) != binary.BigEndian.Uint32(b[n-4:n]) {
+		return 0, nil, 0
+	}
+	if b[0] != walWrite && b[0] != walSeal {
+		return 0, nil, 0
+	}
+	return b[0], append([]byte{}, b[5:n-4]...), n
+}
+
+// appendWALRecord appends the record of type @typ with the data @data to @b.
+func appendWALRecord(b []byte, typ byte, data []byte) []byte {
+	start := len(b)
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	b = append(b, typ)
+	b = append(b, length[:]...)
+	b = append(b, data...)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(b[start:]))
+	return append(b, sum[:]...)
+}
+
+// Pending returns the items written since the last seal when the log was opened.
+func (l *PoolWAL) Pending() [][]byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending
+}
+
+// LastSeal returns the root of the last seal when the log was opened, or nil if the pool
+// was never sealed.
+func (l *PoolWAL) LastSeal() []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastSeal
+}
+
+// LogWrite records that @bs was written to the pool.
+func (l *PoolWAL) LogWrite(bs []byte) error {
+	if uint64(len(bs)) > 1<<32-1 {
+		return ErrItemTooLarge
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return errors.New("error: write-ahead log is closed")
+	}
+	if _, err := l.f.Write(appendWALRecord(nil, walWrite, bs)); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// LogSeal records that the pool was sealed into the tree with root @root. As the items
+// written so far are part of the tree, the log is compacted to the seal record.
+func (l *PoolWAL) LogSeal(root []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return errors.New("error: write-ahead log is closed")
+	}
+	record := appendWALRecord(nil, walSeal, root)
+	if _, err := l.f.Write(record); err != nil {
+		return err
+	}
+	if err := l.f.Sync(); err != nil {
+		return err
+	}
+	// The seal is durable, so a crash during compaction leaves a valid log either way.
+	tmp := l.path + ".tmp"
+	if err := writeFileSync(tmp, record); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(l.path)); err != nil {
+		return err
+	}
+	l.f.Close()
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		l.f = nil
+		return err
+	}
+	l.f = f
+	return nil
+}
+
+// writeFileSync writes @data to the file at @path and syncs it to disk.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir syncs the directory @dir to disk, such that a file renamed into it survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// Close closes the log.
+func (l *PoolWAL) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
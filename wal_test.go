@@ -0,0 +1,241 @@
+package merkletree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bucketContents returns the items of the buckets in the pool of @w, by bucket.
+func bucketContents(t *testing.T, w *BucketWriter) []string {
+	w.Flush()
+	var contents []string
+	for i := w.Pool().Len(); i > 0; i-- {
+		b := <-w.Pool().c
+		sb := bucketToStorage(b)
+		items, err := sb.ReadContent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(bytes.Join(items, []byte(","))))
+		w.Pool().c <- b
+	}
+	return contents
+}
+
+func TestPoolWAL_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.wal")
+	wal, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := RecoverBucketWriter(wal, NewBucketPool(4, 32, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		if err := w.Write([]byte(fmt.Sprintf("item-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Crash without closing the log, leaving a torn record behind.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(appendWALRecord(nil, walWrite, []byte("torn"))[:7])
+	f.Close()
+	size, _ := os.Stat(path)
+
+	recovered, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	if len(recovered.Pending()) != 7 {
+		t.Fatalf("error: expected 7 pending items got %d", len(recovered.Pending()))
+	}
+	if info, _ := os.Stat(path); info.Size() != size.Size()-7 {
+		t.Errorf("error: expected torn record to be discarded")
+	}
+	w2, err := RecoverBucketWriter(recovered, NewBucketPool(4, 32, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := bucketContents(t, w)
+	got := bucketContents(t, w2)
+	if fmt.Sprint(expected) != fmt.Sprint(got) {
+		t.Errorf("error: expected buckets %q got %q", expected, got)
+	}
+
+	// The seal and the items written after it are recorded as well.
+	tree, err := w2.Seal(NewBucketPool(4, 32, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2.Write([]byte("item-8")); err != nil {
+		t.Fatal(err)
+	}
+	recovered.Close()
+
+	sealed, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sealed.Close()
+	if !bytes.Equal(sealed.LastSeal(), tree.MerkleRoot) {
+		t.Errorf("error: expected last seal %x got %x", tree.MerkleRoot, sealed.LastSeal())
+	}
+	if len(sealed.Pending()) != 1 || string(sealed.Pending()[0]) != "item-8" {
+		t.Errorf("error: expected pending item-8 got %q", sealed.Pending())
+	}
+}
+
+func TestPoolWAL_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.wal")
+	var log []byte
+	log = appendWALRecord(log, walWrite, []byte("a"))
+	log = appendWALRecord(log, walWrite, []byte("b"))
+	log[len(log)-1] ^= 1
+	if err := os.WriteFile(path, log, 0644); err != nil {
+		t.Fatal(err)
+	}
+	wal, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	if len(wal.Pending()) != 1 || string(wal.Pending()[0]) != "a" {
+		t.Errorf("error: expected pending item a got %q", wal.Pending())
+	}
+}
+
+func TestPoolRotator_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.wal")
+	var sealed []SealedTree
+	onSeal := func(st SealedTree) { sealed = append(sealed, st) }
+	wal, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := RecoverPoolRotator(wal, 2, 32, "trades", 0, onSeal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := r.Write([]byte(fmt.Sprintf("item-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sealed) != 1 {
+		t.Fatalf("error: expected 1 sealed tree got %d", len(sealed))
+	}
+	// Crash and recover the items written after the seal.
+	wal.Close()
+	wal, err = OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = RecoverPoolRotator(wal, 2, 32, "trades", 0, onSeal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sealed) != 2 {
+		t.Fatalf("error: expected 2 sealed trees got %d", len(sealed))
+	}
+	for i := 0; i < 6; i++ {
+		item := []byte(fmt.Sprintf("item-%d", i))
+		in0, _, _ := DataInStorageTree(item, *sealed[0].Tree)
+		in1, _, _ := DataInStorageTree(item, *sealed[1].Tree)
+		if in0 == in1 {
+			t.Errorf("error: expected item-%d in exactly one sealed tree", i)
+		}
+	}
+}
+
+func TestPoolRotator_CloseWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.wal")
+	wal, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := RecoverPoolRotator(wal, 2, 32, "trades", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write([]byte("item-0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Write([]byte("item-1")); err != ErrClosed {
+		t.Errorf("error: expected ErrClosed for write after close got %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("error: expected second close to succeed got %v", err)
+	}
+	// Nothing was logged after the seal of Close.
+	wal, err = OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Close()
+	if len(wal.Pending()) != 0 {
+		t.Errorf("error: expected no pending items got %q", wal.Pending())
+	}
+}
+
+func TestBucketWriter_LogFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.wal")
+	wal, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := RecoverBucketWriter(wal, NewBucketPool(4, 32, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]byte("item-0")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write that cannot be logged is not written to the bucket.
+	wal.Close()
+	if err := w.Write([]byte("item-1")); err == nil {
+		t.Fatal("error: expected error for write to closed log")
+	}
+	if contents := strings.Join(bucketContents(t, w), ";"); strings.Contains(contents, "item-1") {
+		t.Errorf("error: expected unlogged item not to be written, got %s", contents)
+	}
+
+	// A seal that cannot be logged keeps its tree for a retry.
+	if _, err := w.Seal(NewBucketPool(4, 32, "trades")); err == nil {
+		t.Fatal("error: expected error for seal to closed log")
+	}
+	if err := w.Write([]byte("item-2")); err != ErrPoolExhausted {
+		t.Errorf("error: expected ErrPoolExhausted while seal is pending got %v", err)
+	}
+	reopened, err := OpenPoolWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	w.wal = reopened
+	tree, err := w.Seal(NewBucketPool(4, 32, "trades"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, err := DataInStorageTree([]byte("item-0"), *tree); err != nil || !ok {
+		t.Errorf("error: expected retried seal to hold the written item, got %v, %v", ok, err)
+	}
+	if err := w.Write([]byte("item-3")); err != nil {
+		t.Errorf("error: expected write to the next pool to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package merkletree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// -----------------------------------------------------------------------
+// k-ary trees
+// -----------------------------------------------------------------------
+
+// errNotBinary is returned for Merkle paths of trees with an arity greater than 2.
+var errNotBinary = errors.New("error: tree is not binary, see GetWideProof")
+
+// WideProof is the proof of a leaf of a tree of any arity. For each level from the leafs
+// up to the root, Siblings holds the Arity-1 other children of the node on the path, and
+// Index the position of the node among the children of its parent.
+type WideProof struct {
+	Arity    int
+	Siblings [][][]byte
+	Index    []int64
+}
+
+// NewTreeWithArity creates a new Merkle Tree using the content cs, whose inner nodes have
+// @arity children, and the hash strategy @hashStrategy. Each inner node is the hash of the
+// concatenation of its children. The leafs are padded to a multiple of @arity by duplicates
+// of the last leaf, and an incomplete group at the end of a higher level is filled up by
+// repeating its last node. With an arity of 2 this is the tree of NewTreeWithHashStrategy.
+// Wider trees are shallower, trading larger proofs per level for fewer levels, i.e. fewer
+// node lookups when proving from disk. Leafs of trees with an arity greater than 2 are
+// proven by GetWideProof.
+func NewTreeWithArity(cs []Content, arity int, hashStrategy string) (*MerkleTree, error) {
+	if arity < 2 {
+		return nil, errors.New("error: arity must be at least 2")
+	}
+	if _, ok := GetHashStrategies()[hashStrategy]; !ok {
+		return nil, fmt.Errorf("error: unknown hash strategy %s", hashStrategy)
+	}
+	t := &MerkleTree{
+		HashStrategy: hashStrategy,
+	}
+	if arity != 2 {
+		t.Arity = arity
+	}
+	root, leafs, err := buildWithContent(cs, t)
+	if err != nil {
+		return nil, err
+	}
+	t.Root = root
+	t.Leafs = leafs
+	t.MerkleRoot = root.Hash
+	return t, nil
+}
+
+// Depth returns the number of levels above the leafs.
+func (m *MerkleTree) Depth() int {
+	if len(m.Leafs) == 0 {
+		return 0
+	}
+	depth := 0
+	for n := m.Leafs[0].parent; n != nil; n = n.parent {
+		depth++
+	}
+	return depth
+}
+
+// GetWideProof returns the proof of the leaf at @i for a tree of any arity. Trees that
+// sort pairs are not supported.
+func (m *MerkleTree) GetWideProof(i int) (*WideProof, error) {
+	if i < 0 || i >= m.LeafCount() {
+		return nil, errors.New("error: leaf index out of range")
+	}
+	if m.SortPairs {
+		return nil, errors.New("error: wide proof of tree sorting pairs")
+	}
+	proof := &WideProof{Arity: m.arity()}
+	current := m.Leafs[i]
+	for parent := current.parent; parent != nil; current, parent = parent, parent.parent {
+		// A node repeated to fill up a group is proven at its first position.
+		pos := -1
+		siblings := make([][]byte, 0, proof.Arity-1)
+		for j, c := range parent.children() {
+			if c == current && pos < 0 {
+				pos = j
+				continue
+			}
+			siblings = append(siblings, c.Hash)
+		}
+		proof.Siblings = append(proof.Siblings, siblings)
+		proof.Index = append(proof.Index, int64(pos))
+	}
+	return proof, nil
+}
+
+// VerifyWideProof returns true if hashing @leafHash along @proof with the hash strategy
+// @hashStrategy yields @merkleRoot.
+func VerifyWideProof(leafHash []byte, merkleRoot []byte, proof *WideProof, hashStrategy string) (bool, error) {
+	if proof == nil {
+		return false, errors.New("error: no proof")
+	}
+	if proof.Arity < 2 {
+		return false, errors.New("error: arity must be at least 2")
+	}
+	if len(proof.Siblings) != len(proof.Index) {
+		return false, errors.New("error: merkle path and index differ in length")
+	}
+	if _, ok := GetHashStrategies()[hashStrategy]; !ok {
+		return false, fmt.Errorf("error: unknown hash strategy %s", hashStrategy)
+	}
+	// The nodes are hashed like those of a tree with the hash strategy.
+	m := &MerkleTree{HashStrategy: hashStrategy}
+	hash := leafHash
+	for i, siblings := range proof.Siblings {
+		if len(siblings) != proof.Arity-1 {
+			return false, errors.New("error: wrong number of siblings in proof")
+		}
+		pos := proof.Index[i]
+		if pos < 0 || pos >= int64(proof.Arity) {
+			return false, errors.New("error: proof index out of range")
+		}
+		children := make([][]byte, 0, proof.Arity)
+		children = append(children, siblings[:pos]...)
+		children = append(children, hash)
+		children = append(children, siblings[pos:]...)
+		var err error
+		if hash, err = m.nodeHash(children...); err != nil {
+			return false, err
+		}
+	}
+	return bytes.Equal(hash, merkleRoot), nil
+}
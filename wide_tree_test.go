@@ -0,0 +1,203 @@
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// wideContents returns @n distinct contents.
+func wideContents(n int) []Content {
+	var cs []Content
+	for i := 0; i < n; i++ {
+		cs = append(cs, TestSHA256Content{x: fmt.Sprintf("leaf %d", i)})
+	}
+	return cs
+}
+
+// wideRoot computes the SHA-256 root of a tree with @arity level by level, filling up the
+// last group of each level by repeating its last node.
+func wideRoot(cs []Content, arity int) []byte {
+	var level [][]byte
+	for _, c := range cs {
+		hash, _ := c.CalculateHash()
+		level = append(level, hash)
+	}
+	for first := true; first || len(level) > 1; first = false {
+		var next [][]byte
+		for i := 0; i < len(level); i += arity {
+			var group []byte
+			for j := i; j < i+arity; j++ {
+				k := j
+				if k >= len(level) {
+					k = len(level) - 1
+				}
+				group = append(group, level[k]...)
+			}
+			hash := sha256.Sum256(group)
+			next = append(next, hash[:])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func TestNewTreeWithArity_Root(t *testing.T) {
+	for _, arity := range []int{2, 3, 4, 16} {
+		for n := 1; n <= 17; n++ {
+			cs := wideContents(n)
+			tree, err := NewTreeWithArity(cs, arity, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(tree.MerkleRoot, wideRoot(cs, arity)) {
+				t.Errorf("[arity:%d n:%d] error: unexpected root", arity, n)
+			}
+			if tree.LeafCount() != n {
+				t.Errorf("[arity:%d n:%d] error: expected %d leafs got %d", arity, n, n, tree.LeafCount())
+			}
+			if ok, err := tree.VerifyTree(); err != nil || !ok {
+				t.Errorf("[arity:%d n:%d] error: expected valid tree", arity, n)
+			}
+			if ok, err := tree.VerifyContent(cs[n-1]); err != nil || !ok {
+				t.Errorf("[arity:%d n:%d] error: expected valid content", arity, n)
+			}
+		}
+	}
+	for n := 1; n <= 17; n++ {
+		cs := wideContents(n)
+		binary, err := NewTree(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree, err := NewTreeWithArity(cs, 2, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(binary.MerkleRoot, tree.MerkleRoot) || tree.Arity != 0 {
+			t.Errorf("[n:%d] error: expected tree of arity 2 to be the binary tree", n)
+		}
+	}
+}
+
+func TestMerkleTree_GetWideProof(t *testing.T) {
+	for _, arity := range []int{2, 3, 4, 16} {
+		for _, n := range []int{1, 2, 5, 16, 17, 33} {
+			cs := wideContents(n)
+			tree, err := NewTreeWithArity(cs, arity, "sha256")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range cs {
+				proof, err := tree.GetWideProof(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(proof.Siblings) != tree.Depth() {
+					t.Errorf("[arity:%d n:%d case:%d] error: expected %d levels got %d", arity, n, i, tree.Depth(), len(proof.Siblings))
+				}
+				leafHash, _ := c.CalculateHash()
+				ok, err := VerifyWideProof(leafHash, tree.MerkleRoot, proof, "sha256")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ok {
+					t.Errorf("[arity:%d n:%d case:%d] error: expected valid proof", arity, n, i)
+				}
+				other, _ := TestSHA256Content{x: "absent"}.CalculateHash()
+				if ok, _ := VerifyWideProof(other, tree.MerkleRoot, proof, "sha256"); ok {
+					t.Errorf("[arity:%d n:%d case:%d] error: expected proof of other leaf to be invalid", arity, n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestMerkleTree_Depth(t *testing.T) {
+	cs := wideContents(256)
+	for _, test := range []struct {
+		arity int
+		depth int
+	}{{2, 8}, {4, 4}, {16, 2}} {
+		tree, err := NewTreeWithArity(cs, test.arity, "sha256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tree.Depth() != test.depth {
+			t.Errorf("[arity:%d] error: expected depth %d got %d", test.arity, test.depth, tree.Depth())
+		}
+	}
+}
+
+func TestNewTreeWithArity_JSON(t *testing.T) {
+	tree, err := NewTreeWithArity([]Content{ByteContent{Content: []byte("a")}, ByteContent{Content: []byte("b")}}, 3, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out MerkleTree
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Arity != 3 || len(out.Root.Children) != 3 || !bytes.Equal(out.Root.Children[2].Hash, tree.Leafs[1].Hash) {
+		t.Errorf("error: unexpected unmarshaled tree %v", out)
+	}
+}
+
+func TestMerkleTree_JSONBinary(t *testing.T) {
+	tree, err := NewTree(wideContents(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"SortPairs"`, `"Arity"`, `"Children"`} {
+		if bytes.Contains(data, []byte(key)) {
+			t.Errorf("error: expected no %s in encoding of binary tree", key)
+		}
+	}
+	if (&MerkleTree{}).Depth() != 0 {
+		t.Errorf("error: expected depth 0 of tree without leafs")
+	}
+}
+
+func TestNewTreeWithArity_Errors(t *testing.T) {
+	if _, err := NewTreeWithArity(nil, 4, "sha256"); err == nil {
+		t.Errorf("error: expected error for tree with no content")
+	}
+	if _, err := NewTreeWithArity(wideContents(3), 1, "sha256"); err == nil {
+		t.Errorf("error: expected error for arity 1")
+	}
+	if _, err := NewTreeWithArity(wideContents(3), 4, "md5"); err == nil {
+		t.Errorf("error: expected error for unknown hash strategy")
+	}
+	tree, err := NewTreeWithArity(wideContents(9), 4, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.GetWideProof(9); err == nil {
+		t.Errorf("error: expected error for duplicate leaf")
+	}
+	if _, _, err := tree.GetMerklePathAt(0); err == nil {
+		t.Errorf("error: expected error for merkle path of tree with arity 4")
+	}
+	proof, err := tree.GetWideProof(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafHash, _ := TestSHA256Content{x: "leaf 5"}.CalculateHash()
+	proof.Siblings[0] = proof.Siblings[0][1:]
+	if _, err := VerifyWideProof(leafHash, tree.MerkleRoot, proof, "sha256"); err == nil {
+		t.Errorf("error: expected error for missing sibling")
+	}
+	if _, err := VerifyWideProof(leafHash, tree.MerkleRoot, nil, "sha256"); err == nil {
+		t.Errorf("error: expected error for nil proof")
+	}
+}